@@ -9,7 +9,7 @@ import (
 	"syscall"
 	"time"
 
-	"go-test/internal/server"
+	"go-test/src/internal/server"
 )
 
 func gracefulShutdown(apiServer *http.Server, done chan bool) {
@@ -24,7 +24,8 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 	stop() // Allow Ctrl+C to force shutdown
 
 	// The context is used to inform the server it has 5 seconds to finish
-	// the request it is currently handling
+	// the request it is currently handling. Shutting down also stops the
+	// sampler, which ends the open event streams.
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := apiServer.Shutdown(ctx); err != nil {
@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Names of the built-in collectors.
+const (
+	Cpu              = "cpu"
+	Load             = "load"
+	Pressure         = "pressure"
+	Memory           = "memory"
+	Disks            = "disks"
+	Sensors          = "sensors"
+	Gpu              = "gpu"
+	Network          = "network"
+	Wireless         = "wireless"
+	Connections      = "connections"
+	NetworkProcesses = "network_processes"
+	Processes        = "processes"
+)
+
+// Sample is the typed result of a single collection run, e.g. CpuSample or
+// GpuSample. Consumers type-assert it based on the collector name.
+type Sample any
+
+// Collector gathers one kind of system data.
+type Collector interface {
+	// Name returns the unique name the collector is registered under.
+	Name() string
+
+	// Interval returns how often the collector should be run.
+	Interval() time.Duration
+
+	// Collect reads the current state of the system.
+	// It returns an error if no usable sample could be produced.
+	Collect(ctx context.Context) (Sample, error)
+}
+
+// Registry holds the set of available collectors, keyed by name.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+	order      []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]Collector),
+	}
+}
+
+// NewDefaultRegistry returns a registry with all built-in collectors.
+func NewDefaultRegistry(cfg Config) *Registry {
+	r := NewRegistry()
+	r.MustRegister(NewCpuCollector(cfg))
+	r.MustRegister(NewLoadCollector(cfg))
+	r.MustRegister(NewPressureCollector(cfg, DefaultPressureThresholds))
+	r.MustRegister(NewMemoryCollector(cfg))
+	r.MustRegister(NewDiskCollector(cfg))
+	r.MustRegister(NewSensorsCollector(cfg))
+	r.MustRegister(NewGpuCollector(cfg))
+	r.MustRegister(NewNetworkCollector(cfg))
+	r.MustRegister(NewWirelessCollector(cfg))
+	r.MustRegister(NewConnectionsCollector(cfg))
+	r.MustRegister(NewNetworkProcessesCollector(cfg))
+	r.MustRegister(NewProcessCollector(cfg))
+	return r
+}
+
+// Register adds a collector to the registry.
+// It returns an error if a collector with the same name is already registered.
+func (r *Registry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := c.Name()
+	if _, ok := r.collectors[name]; ok {
+		return fmt.Errorf("collector %q already registered", name)
+	}
+	r.collectors[name] = c
+	r.order = append(r.order, name)
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func (r *Registry) MustRegister(c Collector) {
+	if err := r.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns the collector registered under name.
+func (r *Registry) Get(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collectors[name]
+	return c, ok
+}
+
+// All returns every registered collector in registration order.
+func (r *Registry) All() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]Collector, 0, len(r.order))
+	for _, name := range r.order {
+		all = append(all, r.collectors[name])
+	}
+	return all
+}
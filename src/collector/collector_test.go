@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-test/src/internal/fakecmd"
+)
+
+func TestMain(m *testing.M) {
+	fakecmd.Main()
+	os.Exit(m.Run())
+}
+
+type stubCollector struct {
+	name string
+}
+
+func (c stubCollector) Name() string            { return c.name }
+func (c stubCollector) Interval() time.Duration { return time.Millisecond }
+func (c stubCollector) Collect(ctx context.Context) (Sample, error) {
+	return c.name, nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(stubCollector{name: "b"})
+	r.MustRegister(stubCollector{name: "a"})
+
+	if err := r.Register(stubCollector{name: "a"}); err == nil {
+		t.Errorf("Register accepted a duplicate name")
+	}
+
+	if _, ok := r.Get("a"); !ok {
+		t.Errorf("Get(%q) did not find the collector", "a")
+	}
+	if _, ok := r.Get("missing"); ok {
+		t.Errorf("Get(%q) found a collector that was never registered", "missing")
+	}
+
+	all := r.All()
+	if len(all) != 2 || all[0].Name() != "b" || all[1].Name() != "a" {
+		t.Errorf("All() did not preserve registration order: got %v", all)
+	}
+}
+
+// fixtureConfig returns a Config pointing at a private copy of the fixture
+// tree in testdata/, so tests may rewrite files between samples.
+func fixtureConfig(t *testing.T) Config {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.CopyFS(root, os.DirFS(filepath.Join("..", "..", "testdata"))); err != nil {
+		t.Fatal(err)
+	}
+	return Config{
+		ProcRoot: filepath.Join(root, "proc"),
+		SysRoot:  filepath.Join(root, "sys"),
+	}
+}
+
+// writeFixture replaces a file in the fixture tree, relative to the proc root.
+func writeFixture(t *testing.T, cfg Config, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(cfg.procPath(name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/shirou/gopsutil/v3/common"
+
+	"go-test/src/procfs"
+)
+
+// Config tells collectors where the proc and sys filesystems are mounted.
+// Tests point it at a fixture tree instead of the real machine.
+type Config struct {
+	ProcRoot string
+	SysRoot  string
+
+	// Hwmon sensors shown as the CPU temperature and the CPU and GPU fans,
+	// as "chip/label" (e.g. "nct6775/fan2"). See SensorReading.Matches for
+	// the accepted forms. When empty the collectors guess.
+	CpuTempSensor string
+	CpuFanSensor  string
+	GpuFanSensor  string
+}
+
+// DefaultConfig reads the real machine. The sensor mapping comes from the
+// CPU_TEMP_SENSOR, CPU_FAN_SENSOR and GPU_FAN_SENSOR environment variables.
+func DefaultConfig() Config {
+	return Config{
+		ProcRoot: procfs.DefaultMountPoint,
+		SysRoot:  "/sys",
+
+		CpuTempSensor: os.Getenv("CPU_TEMP_SENSOR"),
+		CpuFanSensor:  os.Getenv("CPU_FAN_SENSOR"),
+		GpuFanSensor:  os.Getenv("GPU_FAN_SENSOR"),
+	}
+}
+
+func (c Config) procFS() procfs.FS {
+	return procfs.NewFS(c.ProcRoot)
+}
+
+// procPath joins elem onto the proc root.
+func (c Config) procPath(elem ...string) string {
+	return filepath.Join(append([]string{c.ProcRoot}, elem...)...)
+}
+
+// sysPath joins elem onto the sys root.
+func (c Config) sysPath(elem ...string) string {
+	return filepath.Join(append([]string{c.SysRoot}, elem...)...)
+}
+
+// context returns ctx carrying the roots in the form gopsutil expects.
+func (c Config) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, common.EnvKey, common.EnvMap{
+		common.HostProcEnvKey: c.ProcRoot,
+		common.HostSysEnvKey:  c.SysRoot,
+	})
+}
@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// socketProtocols are the socket tables read, in display order.
+var socketProtocols = []string{"tcp", "tcp6", "udp", "udp6"}
+
+// tcpStates names the states of include/net/tcp_states.h.
+var tcpStates = map[int]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+	12: "NEW_SYN_RECV",
+}
+
+// Connection is one TCP or UDP socket.
+type Connection struct {
+	Protocol   string `json:"protocol"` // "tcp", "tcp6", "udp" or "udp6"
+	LocalAddr  string `json:"local_addr"`
+	LocalPort  int    `json:"local_port"`
+	RemoteAddr string `json:"remote_addr"`
+	RemotePort int    `json:"remote_port"`
+	State      string `json:"state"` // e.g. "ESTABLISHED"; unconnected UDP sockets are "UNCONN"
+	UID        int    `json:"uid"`
+	Pid        int    `json:"pid"`     // 0 when the owner is unknown or not readable
+	Process    string `json:"process"` // empty when Pid is 0
+	TxQueue    uint64 `json:"tx_queue"`
+	RxQueue    uint64 `json:"rx_queue"`
+}
+
+// Listening reports whether the socket waits for connections or datagrams
+// from anyone.
+func (c Connection) Listening() bool {
+	return c.State == "LISTEN" || c.State == "UNCONN"
+}
+
+// Local returns the local address and port, e.g. "[::1]:631".
+func (c Connection) Local() string {
+	return net.JoinHostPort(c.LocalAddr, strconv.Itoa(c.LocalPort))
+}
+
+// Remote returns the remote address and port, or "*:*" if there is none.
+func (c Connection) Remote() string {
+	if c.RemotePort == 0 && net.ParseIP(c.RemoteAddr).IsUnspecified() {
+		return "*:*"
+	}
+	return net.JoinHostPort(c.RemoteAddr, strconv.Itoa(c.RemotePort))
+}
+
+type ConnectionsSample struct {
+	Connections []Connection `json:"connections"`
+
+	// States counts the TCP sockets in each state.
+	States map[string]int `json:"states"`
+}
+
+// ConnectionsCollector lists the sockets of /proc/net/{tcp,tcp6,udp,udp6}
+// and finds their owners through /proc/[pid]/fd, like ss -tuap. Without
+// root only the sockets of our own user have a known owner.
+type ConnectionsCollector struct {
+	fs procfs.FS
+}
+
+func NewConnectionsCollector(cfg Config) *ConnectionsCollector {
+	return &ConnectionsCollector{fs: cfg.procFS()}
+}
+
+func (c *ConnectionsCollector) Name() string { return Connections }
+
+func (c *ConnectionsCollector) Interval() time.Duration { return 3 * time.Second }
+
+func (c *ConnectionsCollector) Collect(ctx context.Context) (Sample, error) {
+	s := ConnectionsSample{States: make(map[string]int)}
+	inodes := make(map[uint64][]int) // inode to indexes into s.Connections
+	read := 0
+	for _, protocol := range socketProtocols {
+		sockets, err := c.fs.NetSockets(protocol)
+		if errors.Is(err, fs.ErrNotExist) {
+			// No IPv6, or no UDP in a minimal kernel
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		read++
+
+		udp := protocol == "udp" || protocol == "udp6"
+		for _, sock := range sockets {
+			conn := Connection{
+				Protocol:   protocol,
+				LocalAddr:  sock.LocalAddr.String(),
+				LocalPort:  sock.LocalPort,
+				RemoteAddr: sock.RemoteAddr.String(),
+				RemotePort: sock.RemotePort,
+				State:      tcpStates[sock.State],
+				UID:        sock.UID,
+				TxQueue:    sock.TxQueue,
+				RxQueue:    sock.RxQueue,
+			}
+			switch {
+			case udp && sock.State == 7:
+				conn.State = "UNCONN"
+			case !udp:
+				s.States[conn.State]++
+			}
+			if sock.Inode != 0 {
+				inodes[sock.Inode] = append(inodes[sock.Inode], len(s.Connections))
+			}
+			s.Connections = append(s.Connections, conn)
+		}
+	}
+	if read == 0 {
+		return nil, errors.New("no socket tables in " + c.fs.Path("net"))
+	}
+
+	if err := c.findOwners(ctx, s.Connections, inodes); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(s.Connections, func(i, j int) bool {
+		a, b := s.Connections[i], s.Connections[j]
+		if a.Listening() != b.Listening() {
+			return a.Listening()
+		}
+		return a.LocalPort < b.LocalPort
+	})
+	return s, nil
+}
+
+// findOwners sets the process of each connection whose socket inode is
+// open in one of the processes we may look into.
+func (c *ConnectionsCollector) findOwners(ctx context.Context, conns []Connection, inodes map[uint64][]int) error {
+	if len(inodes) == 0 {
+		return nil
+	}
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return err
+	}
+	for _, p := range procs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Processes of other users and those that exited are skipped
+		owned, err := p.SocketInodes()
+		if err != nil {
+			continue
+		}
+		name := ""
+		for _, inode := range owned {
+			for _, i := range inodes[inode] {
+				if name == "" {
+					if stat, err := p.Stat(); err == nil {
+						name = stat.Comm
+					}
+				}
+				conns[i].Pid = p.PID
+				conns[i].Process = name
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestConnectionsCollector(t *testing.T) {
+	sample, err := NewConnectionsCollector(fixtureConfig(t)).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(ConnectionsSample)
+
+	want := []struct {
+		protocol, local, remote, state string
+		pid                            int
+		process                        string
+	}{
+		// Listening sockets first, by port
+		{"tcp", "0.0.0.0:22", "*:*", "LISTEN", 300, "sshd"},
+		{"tcp6", "[::]:22", "*:*", "LISTEN", 300, "sshd"},
+		{"udp", "0.0.0.0:68", "*:*", "UNCONN", 300, "sshd"},
+		{"tcp", "127.0.0.1:631", "*:*", "LISTEN", 0, ""},
+		{"udp6", "[::]:5353", "*:*", "UNCONN", 0, ""},
+		{"tcp6", "[fd00::2]:22", "[fd00::99]:50122", "ESTABLISHED", 300, "sshd"},
+		{"udp", "192.168.1.20:41000", "1.1.1.1:53", "ESTABLISHED", 0, ""},
+		{"tcp", "192.168.1.20:53412", "93.184.216.34:80", "TIME_WAIT", 0, ""},
+		{"tcp", "192.168.1.20:54000", "140.82.112.3:443", "ESTABLISHED", 100, "Web Content"},
+	}
+	if len(s.Connections) != len(want) {
+		t.Fatalf("got %d connections, want %d: %+v", len(s.Connections), len(want), s.Connections)
+	}
+	for i, w := range want {
+		c := s.Connections[i]
+		if c.Protocol != w.protocol || c.Local() != w.local || c.Remote() != w.remote || c.State != w.state || c.Pid != w.pid || c.Process != w.process {
+			t.Errorf("connection %d = %s %s %s %s %d %q, want %+v", i, c.Protocol, c.Local(), c.Remote(), c.State, c.Pid, c.Process, w)
+		}
+	}
+
+	states := map[string]int{"LISTEN": 3, "ESTABLISHED": 2, "TIME_WAIT": 1}
+	if len(s.States) != len(states) {
+		t.Errorf("States = %v, want %v", s.States, states)
+	}
+	for state, n := range states {
+		if s.States[state] != n {
+			t.Errorf("States[%s] = %d, want %d", state, s.States[state], n)
+		}
+	}
+}
+
+func TestConnectionsCollectorNoIPv6(t *testing.T) {
+	cfg := fixtureConfig(t)
+	for _, name := range []string{"tcp6", "udp6"} {
+		if err := os.Remove(cfg.procPath("net", name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sample, err := NewConnectionsCollector(cfg).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sample.(ConnectionsSample).Connections); n != 6 {
+		t.Errorf("got %d connections, want the 6 IPv4 ones", n)
+	}
+
+	writeFixture(t, cfg, "net/tcp", `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1003 1
+`)
+	if _, err := NewConnectionsCollector(cfg).Collect(context.Background()); err == nil {
+		t.Errorf("malformed socket table did not fail")
+	}
+}
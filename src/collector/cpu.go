@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-test/src/procfs"
+
+	cpu "github.com/shirou/gopsutil/v3/cpu"
+)
+
+type CpuSample struct {
+	Name      string       `json:"name"`
+	FreqMHz   float64      `json:"freq_mhz"` // average over all cores
+	Usage     float64      `json:"usage_percent"`
+	Breakdown CpuBreakdown `json:"breakdown"`
+	Cores     []CoreSample `json:"cores"`
+	Temp      float64      `json:"temp_celsius"`
+	FanSpeed  string       `json:"fan_speed"`
+}
+
+type CpuCollector struct {
+	cfg Config
+
+	mu        sync.Mutex
+	last      procfs.CpuStat
+	lastCores map[int]procfs.CpuStat
+}
+
+func NewCpuCollector(cfg Config) *CpuCollector {
+	return &CpuCollector{cfg: cfg, lastCores: make(map[int]procfs.CpuStat)}
+}
+
+func (c *CpuCollector) Name() string { return Cpu }
+
+func (c *CpuCollector) Interval() time.Duration { return time.Second }
+
+func (c *CpuCollector) Collect(ctx context.Context) (Sample, error) {
+	s := CpuSample{
+		FanSpeed: "N/A",
+	}
+
+	if stat, err := c.cfg.procFS().Stat(); err == nil {
+		s.Breakdown, s.Cores = c.usage(stat)
+		s.Usage = s.Breakdown.Busy()
+	}
+
+	ctx = c.cfg.context(ctx)
+	cpuInfo, err := cpu.InfoWithContext(ctx)
+	if err != nil {
+		s.Name = "N/A"
+	} else if len(cpuInfo) > 0 {
+		s.Name = cpuInfo[0].ModelName
+		s.FreqMHz = cpuInfo[0].Mhz
+	} else {
+		s.Name = "Unknown"
+	}
+	if freq := averageFreq(s.Cores); freq > 0 {
+		// cpufreq is current for every core, cpuinfo may only show core 0
+		s.FreqMHz = freq
+	}
+
+	if readings, err := c.cfg.hwmon(); err == nil {
+		if r, ok := c.cfg.cpuPackage(readings); ok {
+			s.Temp = r.Value
+		}
+		if fan, ok := fanSpeed(readings, c.cfg.CpuFanSensor, "cpu"); ok {
+			s.FanSpeed = fan
+		}
+	}
+
+	return s, nil
+}
+
+// usage returns the time breakdown since the previous call, overall and for
+// each core, along with the frequency and type of each core.
+func (c *CpuCollector) usage(stat procfs.Stat) (CpuBreakdown, []CoreSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := cpuBreakdown(c.last, stat.CpuTotal)
+	c.last = stat.CpuTotal
+
+	ids := make([]int, len(stat.Cpu))
+	for i, cur := range stat.Cpu {
+		ids[i] = cur.ID
+	}
+	types := c.cfg.coreTypes(ids)
+
+	cores := make([]CoreSample, len(stat.Cpu))
+	for i, cur := range stat.Cpu {
+		b := cpuBreakdown(c.lastCores[cur.ID], cur)
+		c.lastCores[cur.ID] = cur
+
+		cores[i] = CoreSample{
+			ID:        cur.ID,
+			Type:      types[cur.ID],
+			Usage:     b.Busy(),
+			Breakdown: b,
+		}
+		cores[i].FreqMHz, cores[i].MaxFreqMHz = c.cfg.coreFreq(cur.ID)
+	}
+	return total, cores
+}
+
+func averageFreq(cores []CoreSample) float64 {
+	var sum float64
+	var n int
+	for _, core := range cores {
+		if core.FreqMHz > 0 {
+			sum += core.FreqMHz
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
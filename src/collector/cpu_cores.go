@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-test/src/procfs"
+)
+
+// Core types of hybrid CPUs.
+const (
+	PerformanceCore = "P-core"
+	EfficiencyCore  = "E-core"
+)
+
+// CpuBreakdown splits CPU time into modes, as percentages of the elapsed time.
+type CpuBreakdown struct {
+	User   float64 `json:"user"` // including nice
+	System float64 `json:"system"`
+	Iowait float64 `json:"iowait"`
+	IRQ    float64 `json:"irq"` // hard and soft interrupts
+	Steal  float64 `json:"steal"`
+	Idle   float64 `json:"idle"`
+}
+
+// Busy returns the percentage of time not spent idle or waiting for I/O.
+func (b CpuBreakdown) Busy() float64 {
+	return b.User + b.System + b.IRQ + b.Steal
+}
+
+// CoreSample describes one logical CPU.
+type CoreSample struct {
+	ID         int          `json:"id"`
+	Type       string       `json:"type,omitempty"` // PerformanceCore, EfficiencyCore or "" if not hybrid
+	Usage      float64      `json:"usage_percent"`
+	FreqMHz    float64      `json:"freq_mhz"`
+	MaxFreqMHz float64      `json:"max_freq_mhz"`
+	Breakdown  CpuBreakdown `json:"breakdown"`
+}
+
+// cpuBreakdown returns the share of each mode between two readings.
+func cpuBreakdown(last, cur procfs.CpuStat) CpuBreakdown {
+	total := cur.Total() - last.Total()
+	if last.Total() == 0 || cur.Total() <= last.Total() {
+		return CpuBreakdown{}
+	}
+	pct := func(cur, last uint64) float64 {
+		if cur < last {
+			return 0
+		}
+		return float64(cur-last) / float64(total) * 100
+	}
+	return CpuBreakdown{
+		User:   pct(cur.User+cur.Nice, last.User+last.Nice),
+		System: pct(cur.System, last.System),
+		Iowait: pct(cur.Iowait, last.Iowait),
+		IRQ:    pct(cur.IRQ+cur.SoftIRQ, last.IRQ+last.SoftIRQ),
+		Steal:  pct(cur.Steal, last.Steal),
+		Idle:   pct(cur.Idle, last.Idle),
+	}
+}
+
+// coreFreq returns the current and maximum frequency of a CPU in MHz, from
+// cpufreq. Both are 0 if the driver does not expose them.
+func (c Config) coreFreq(id int) (cur, peak float64) {
+	dir := c.sysPath("devices/system/cpu", "cpu"+strconv.Itoa(id), "cpufreq")
+	read := func(name string) float64 {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return 0
+		}
+		khz, _ := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		return khz / 1000
+	}
+	return read("scaling_cur_freq"), read("cpuinfo_max_freq")
+}
+
+// coreTypes maps CPU IDs to their core type on hybrid CPUs. Intel lists the
+// CPUs of each PMU under /sys/devices/cpu_core and cpu_atom; elsewhere cores
+// are told apart by cpu_capacity, the largest being the performance cores.
+// It returns nil if all cores are alike.
+func (c Config) coreTypes(ids []int) map[int]string {
+	types := make(map[int]string)
+	for dir, typ := range map[string]string{"cpu_core": PerformanceCore, "cpu_atom": EfficiencyCore} {
+		data, err := os.ReadFile(c.sysPath("devices", dir, "cpus"))
+		if err != nil {
+			continue
+		}
+		for _, id := range parseCpuList(strings.TrimSpace(string(data))) {
+			types[id] = typ
+		}
+	}
+	if len(types) > 0 {
+		return types
+	}
+
+	capacity := make(map[int]uint64)
+	var largest uint64
+	for _, id := range ids {
+		data, err := os.ReadFile(c.sysPath("devices/system/cpu", "cpu"+strconv.Itoa(id), "cpu_capacity"))
+		if err != nil {
+			return nil
+		}
+		capacity[id], _ = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		largest = max(largest, capacity[id])
+	}
+	hybrid := false
+	for id, size := range capacity {
+		types[id] = PerformanceCore
+		if size < largest {
+			types[id] = EfficiencyCore
+			hybrid = true
+		}
+	}
+	if !hybrid {
+		return nil
+	}
+	return types
+}
+
+// parseCpuList parses the kernel's CPU list format, e.g. "0-3,8,10-11".
+func parseCpuList(s string) []int {
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		for id := first; id <= last; id++ {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCpuCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewCpuCollector(cfg)
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(CpuSample)
+	if s.Name != "Fixture CPU @ 3.00GHz" {
+		t.Errorf("Name = %q, want %q", s.Name, "Fixture CPU @ 3.00GHz")
+	}
+	// cpufreq wins over the 2400 MHz cpuinfo reports for core 0
+	if s.FreqMHz != 2500 {
+		t.Errorf("FreqMHz = %v, want 2500", s.FreqMHz)
+	}
+	if len(s.Cores) != 2 {
+		t.Fatalf("got %d cores, want 2", len(s.Cores))
+	}
+	if c := s.Cores[0]; c.ID != 0 || c.Type != PerformanceCore || c.FreqMHz != 3000 || c.MaxFreqMHz != 4800 {
+		t.Errorf("core 0 = %+v", c)
+	}
+	if c := s.Cores[1]; c.ID != 1 || c.Type != EfficiencyCore || c.FreqMHz != 2000 || c.MaxFreqMHz != 3600 {
+		t.Errorf("core 1 = %+v", c)
+	}
+	if s.Temp != 52 {
+		t.Errorf("Temp = %v, want 52", s.Temp)
+	}
+	if s.FanSpeed != "1200 RPM" {
+		t.Errorf("FanSpeed = %q, want %q", s.FanSpeed, "1200 RPM")
+	}
+	if s.Usage != 0 {
+		t.Errorf("first Usage = %v, want 0 without a previous sample", s.Usage)
+	}
+
+	// 100 more busy ticks and 300 more idle ticks: 25% busy, most of it on
+	// core 0
+	writeFixture(t, cfg, "stat", "cpu  1080 0 520 8250 150 0 0 0 0 0\ncpu0 570 0 270 4060 50 0 0 0 0 0\ncpu1 510 0 250 4190 100 0 0 0 0 0\n")
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = sample.(CpuSample)
+	if math.Abs(s.Usage-25) > 0.001 {
+		t.Errorf("Usage = %v, want 25", s.Usage)
+	}
+	want := CpuBreakdown{User: 20, System: 5, Iowait: 12.5, Idle: 62.5}
+	if s.Breakdown != want {
+		t.Errorf("Breakdown = %+v, want %+v", s.Breakdown, want)
+	}
+	if got := s.Cores[0].Usage; math.Abs(got-60) > 0.001 {
+		t.Errorf("core 0 Usage = %v, want 60", got)
+	}
+	if got := s.Cores[1].Breakdown; math.Abs(got.Busy()-4) > 0.001 || math.Abs(got.Iowait-20) > 0.001 {
+		t.Errorf("core 1 Breakdown = %+v, want 4%% busy and 20%% iowait", got)
+	}
+}
+
+func TestCoreTypesFromCapacity(t *testing.T) {
+	cfg := Config{SysRoot: t.TempDir()}
+	for id, capacity := range []string{"1024", "1024", "446", "446"} {
+		dir := cfg.sysPath("devices/system/cpu", fmt.Sprintf("cpu%d", id))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "cpu_capacity"), []byte(capacity+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	types := cfg.coreTypes([]int{0, 1, 2, 3})
+	want := map[int]string{0: PerformanceCore, 1: PerformanceCore, 2: EfficiencyCore, 3: EfficiencyCore}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Errorf("coreTypes = %v, want %v", types, want)
+	}
+
+	if types := cfg.coreTypes([]int{0, 1}); types != nil {
+		t.Errorf("identical cores were given types: %v", types)
+	}
+}
+
+func TestParseCpuList(t *testing.T) {
+	tests := map[string][]int{
+		"0":           {0},
+		"0-3":         {0, 1, 2, 3},
+		"0-1,8,10-11": {0, 1, 8, 10, 11},
+		"":            nil,
+	}
+	for in, want := range tests {
+		if got := parseCpuList(in); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("parseCpuList(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
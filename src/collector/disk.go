@@ -0,0 +1,315 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// PseudoFilesystems are filesystem types that do not store user data. They
+// are still reported, but marked so views can hide them.
+var PseudoFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true,
+	"cgroup2": true, "configfs": true, "debugfs": true, "devpts": true,
+	"devtmpfs": true, "efivarfs": true, "fusectl": true, "hugetlbfs": true,
+	"mqueue": true, "nsfs": true, "proc": true, "pstore": true,
+	"ramfs": true, "rpc_pipefs": true, "securityfs": true, "squashfs": true,
+	"sysfs": true, "tmpfs": true, "tracefs": true,
+}
+
+// errStatfsTimeout is returned for mounts that do not answer statfs in time.
+var errStatfsTimeout = errors.New("statfs timed out")
+
+// FilesystemStat is the capacity of one mounted filesystem, in bytes.
+type FilesystemStat struct {
+	Device     string `json:"device"`
+	MountPoint string `json:"mount_point"`
+	FSType     string `json:"fs_type"`
+	Pseudo     bool   `json:"pseudo"`
+
+	// Err explains why the capacity is unknown, e.g. a network mount whose
+	// server stopped answering. Such mounts are still listed.
+	Err string `json:"err,omitempty"`
+
+	Size uint64 `json:"size"`
+	Used uint64 `json:"used"`
+	Free uint64 `json:"free"` // available to unprivileged users
+
+	Inodes     uint64 `json:"inodes"`
+	InodesUsed uint64 `json:"inodes_used"`
+	InodesFree uint64 `json:"inodes_free"`
+
+	// FillRate is the smoothed growth of Used. FullInSeconds predicts when
+	// the filesystem reaches 100% at that rate; it is nil while the trend is
+	// still being learned or usage is not growing.
+	FillRate      float64  `json:"fill_rate_bytes_per_sec"`
+	FullInSeconds *float64 `json:"full_in_seconds"`
+}
+
+// FullIn returns the predicted time until the filesystem is full.
+func (f FilesystemStat) FullIn() (time.Duration, bool) {
+	if f.FullInSeconds == nil {
+		return 0, false
+	}
+	return time.Duration(*f.FullInSeconds * float64(time.Second)), true
+}
+
+// UsedPercent returns the share of the space usable by unprivileged users
+// that is taken, like df does.
+func (f FilesystemStat) UsedPercent() float64 {
+	if f.Used+f.Free == 0 {
+		return 0
+	}
+	return float64(f.Used) / float64(f.Used+f.Free) * 100
+}
+
+func (f FilesystemStat) InodesUsedPercent() float64 {
+	if f.Inodes == 0 {
+		return 0
+	}
+	return float64(f.InodesUsed) / float64(f.Inodes) * 100
+}
+
+// BlockDeviceStat is the I/O activity of one block device since the
+// previous sample. All figures are 0 on the first sample.
+type BlockDeviceStat struct {
+	Name   string `json:"name"`
+	Pseudo bool   `json:"pseudo"` // loop, ram and zram devices
+
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	ReadIOPS         float64 `json:"read_iops"`
+	WriteIOPS        float64 `json:"write_iops"`
+	Utilization      float64 `json:"utilization_percent"`
+
+	// Average time per completed request, including queueing.
+	ReadLatencyMs  float64 `json:"read_latency_ms"`
+	WriteLatencyMs float64 `json:"write_latency_ms"`
+}
+
+type DiskSample struct {
+	Filesystems []FilesystemStat  `json:"filesystems"`
+	Devices     []BlockDeviceStat `json:"devices"`
+}
+
+// statfsFunc reports the capacity of the filesystem mounted at path.
+type statfsFunc func(path string) (FilesystemStat, error)
+
+type DiskCollector struct {
+	cfg           Config
+	statfs        statfsFunc
+	statfsTimeout time.Duration // per mount
+
+	mu        sync.Mutex
+	last      map[string]procfs.DiskStat
+	lastTime  time.Time
+	forecasts map[string]*holtForecast // keyed by mount point
+
+	// hung holds the mounts whose statfs timed out, until that call
+	// returns. They are skipped meanwhile rather than piling up calls.
+	hung map[string]chan struct{}
+}
+
+func NewDiskCollector(cfg Config) *DiskCollector {
+	return &DiskCollector{
+		cfg:           cfg,
+		statfs:        statfs,
+		statfsTimeout: 2 * time.Second,
+		last:          make(map[string]procfs.DiskStat),
+		forecasts:     make(map[string]*holtForecast),
+		hung:          make(map[string]chan struct{}),
+	}
+}
+
+func (c *DiskCollector) Name() string { return Disks }
+
+func (c *DiskCollector) Interval() time.Duration { return 2 * time.Second }
+
+func (c *DiskCollector) Collect(ctx context.Context) (Sample, error) {
+	fs := c.cfg.procFS()
+	mounts, err := fs.Mounts()
+	if err != nil {
+		return nil, err
+	}
+
+	s := DiskSample{}
+	for _, m := range mounts {
+		f, err := c.statMount(ctx, m.MountPoint)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			// Stale network mounts and mount points we may not enter
+			f = FilesystemStat{Err: err.Error()}
+		}
+		f.Device = m.Device
+		f.MountPoint = m.MountPoint
+		f.FSType = m.FSType
+		f.Pseudo = PseudoFilesystems[m.FSType] || f.Size == 0 && f.Err == ""
+		s.Filesystems = append(s.Filesystems, f)
+	}
+	c.forecast(s.Filesystems, time.Now())
+
+	stats, err := fs.DiskStats()
+	if err != nil {
+		return nil, err
+	}
+	s.Devices = c.devices(stats)
+	return s, nil
+}
+
+// forecast feeds the usage of each real filesystem into its fill-rate
+// forecast and fills in the prediction.
+func (c *DiskCollector) forecast(filesystems []FilesystemStat, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for i := range filesystems {
+		f := &filesystems[i]
+		if f.Pseudo {
+			continue
+		}
+		seen[f.MountPoint] = true
+		// Keep the trend of a mount that is briefly unavailable
+		if f.Err != "" {
+			continue
+		}
+
+		h, ok := c.forecasts[f.MountPoint]
+		if !ok {
+			h = &holtForecast{}
+			c.forecasts[f.MountPoint] = h
+		}
+		h.Update(now, float64(f.Used))
+		f.FillRate = h.Rate()
+		if d, ok := h.Until(float64(f.Used + f.Free)); ok {
+			seconds := d.Seconds()
+			f.FullInSeconds = &seconds
+		}
+	}
+	// Forget unmounted filesystems
+	for mp := range c.forecasts {
+		if !seen[mp] {
+			delete(c.forecasts, mp)
+		}
+	}
+}
+
+func (c *DiskCollector) devices(stats []procfs.DiskStat) []BlockDeviceStat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	first := c.lastTime.IsZero()
+
+	var devices []BlockDeviceStat
+	for _, cur := range stats {
+		// Only whole disks are listed in /sys/block, not partitions
+		if _, err := os.Stat(c.cfg.sysPath("block", cur.Name)); err != nil {
+			continue
+		}
+		d := BlockDeviceStat{
+			Name:   cur.Name,
+			Pseudo: strings.HasPrefix(cur.Name, "loop") || strings.HasPrefix(cur.Name, "ram") || strings.HasPrefix(cur.Name, "zram"),
+		}
+		if last, ok := c.last[cur.Name]; ok && !first {
+			reads := rate(last.ReadsCompleted, cur.ReadsCompleted, elapsed)
+			writes := rate(last.WritesCompleted, cur.WritesCompleted, elapsed)
+			d.ReadIOPS = reads
+			d.WriteIOPS = writes
+			d.ReadBytesPerSec = rate(last.SectorsRead, cur.SectorsRead, elapsed) * procfs.SectorSize
+			d.WriteBytesPerSec = rate(last.SectorsWritten, cur.SectorsWritten, elapsed) * procfs.SectorSize
+			// IOTime is in milliseconds
+			d.Utilization = min(100, rate(last.IOTime, cur.IOTime, elapsed)/10)
+			d.ReadLatencyMs = latency(last.ReadTime, cur.ReadTime, last.ReadsCompleted, cur.ReadsCompleted)
+			d.WriteLatencyMs = latency(last.WriteTime, cur.WriteTime, last.WritesCompleted, cur.WritesCompleted)
+		}
+		c.last[cur.Name] = cur
+		devices = append(devices, d)
+	}
+	c.lastTime = now
+	return devices
+}
+
+// latency returns the average milliseconds per request completed between
+// two readings.
+func latency(lastTime, curTime, lastOps, curOps uint64) float64 {
+	if curOps <= lastOps || curTime < lastTime {
+		return 0
+	}
+	return float64(curTime-lastTime) / float64(curOps-lastOps)
+}
+
+// statMount runs statfs on path, giving up after c.statfsTimeout. A hard
+// mounted NFS or CIFS share whose server is gone blocks statfs forever, so
+// the call is left running and the mount skipped until it returns.
+func (c *DiskCollector) statMount(ctx context.Context, path string) (FilesystemStat, error) {
+	c.mu.Lock()
+	if done, ok := c.hung[path]; ok {
+		select {
+		case <-done:
+			delete(c.hung, path)
+		default:
+			c.mu.Unlock()
+			return FilesystemStat{}, errStatfsTimeout
+		}
+	}
+	c.mu.Unlock()
+
+	type result struct {
+		f   FilesystemStat
+		err error
+	}
+	results := make(chan result, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f, err := c.statfs(path)
+		results <- result{f, err}
+	}()
+
+	timer := time.NewTimer(c.statfsTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.f, r.err
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	c.mu.Lock()
+	c.hung[path] = done
+	c.mu.Unlock()
+	if ctx.Err() != nil {
+		return FilesystemStat{}, ctx.Err()
+	}
+	return FilesystemStat{}, errStatfsTimeout
+}
+
+func statfs(path string) (FilesystemStat, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return FilesystemStat{}, err
+	}
+	bsize := uint64(st.Bsize)
+	f := FilesystemStat{
+		Size:       st.Blocks * bsize,
+		Free:       st.Bavail * bsize,
+		Inodes:     st.Files,
+		InodesFree: st.Ffree,
+	}
+	if st.Blocks > st.Bfree {
+		f.Used = (st.Blocks - st.Bfree) * bsize
+	}
+	if st.Files > st.Ffree {
+		f.InodesUsed = st.Files - st.Ffree
+	}
+	return f, nil
+}
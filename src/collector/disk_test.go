@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiskCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewDiskCollector(cfg)
+	c.statfs = func(path string) (FilesystemStat, error) {
+		switch path {
+		case "/":
+			return FilesystemStat{Size: 100 << 30, Used: 60 << 30, Free: 35 << 30, Inodes: 1000, InodesUsed: 250, InodesFree: 750}, nil
+		case "/mnt/backup disk":
+			return FilesystemStat{}, errors.New("stale file handle")
+		case "/proc", "/sys":
+			return FilesystemStat{}, nil
+		case "/run":
+			return FilesystemStat{Size: 1600000 << 10, Used: 2 << 20, Free: 1600000<<10 - 2<<20}, nil
+		}
+		return FilesystemStat{Size: 1 << 20, Free: 1 << 20}, nil
+	}
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(DiskSample)
+
+	mounts := make(map[string]FilesystemStat)
+	for _, f := range s.Filesystems {
+		mounts[f.MountPoint] = f
+	}
+	if len(mounts) != 6 {
+		t.Errorf("got %d filesystems, want 6: %v", len(mounts), mounts)
+	}
+	if stale := mounts["/mnt/backup disk"]; stale.Err != "stale file handle" || stale.Pseudo || stale.FullInSeconds != nil {
+		t.Errorf("stale mount = %+v, want it listed with its error", stale)
+	}
+	root := mounts["/"]
+	if root.Device != "/dev/nvme0n1p2" || root.FSType != "ext4" || root.Pseudo {
+		t.Errorf("root = %+v", root)
+	}
+	if math.Abs(root.UsedPercent()-60.0/95*100) > 0.001 || root.InodesUsedPercent() != 25 {
+		t.Errorf("root used %v%% inodes %v%%", root.UsedPercent(), root.InodesUsedPercent())
+	}
+	for _, mp := range []string{"/proc", "/sys", "/run", "/snap/core/1"} {
+		if !mounts[mp].Pseudo {
+			t.Errorf("%s is not marked pseudo", mp)
+		}
+	}
+	// Pseudo filesystems with a size, such as tmpfs, keep their capacity
+	if run := mounts["/run"]; run.Size != 1600000<<10 || run.Used != 2<<20 {
+		t.Errorf("/run = %+v, want its tmpfs capacity", run)
+	}
+	if snap := mounts["/snap/core/1"]; snap.Size != 1<<20 {
+		t.Errorf("/snap/core/1 = %+v, want its squashfs capacity", snap)
+	}
+
+	var names []string
+	for _, d := range s.Devices {
+		names = append(names, d.Name)
+	}
+	if len(names) != 3 || names[0] != "loop0" || names[1] != "nvme0n1" || names[2] != "sda" {
+		t.Errorf("devices = %v, want whole disks only", names)
+	}
+	if !s.Devices[0].Pseudo || s.Devices[1].Pseudo {
+		t.Errorf("loop0 pseudo %v, nvme0n1 pseudo %v", s.Devices[0].Pseudo, s.Devices[1].Pseudo)
+	}
+	if s.Devices[1].ReadIOPS != 0 {
+		t.Errorf("first sample has rates: %+v", s.Devices[1])
+	}
+
+	c.lastTime = c.lastTime.Add(-2 * time.Second)
+	writeFixture(t, cfg, "diskstats", " 259       0 nvme0n1 10200 500 816000 5400 20100 1000 1600800 40500 0 31000 46000 0 0 0 0\n")
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = sample.(DiskSample)
+	if len(s.Devices) != 1 {
+		t.Fatalf("got %d devices, want 1", len(s.Devices))
+	}
+	d := s.Devices[0]
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"ReadIOPS", d.ReadIOPS, 100},
+		{"WriteIOPS", d.WriteIOPS, 50},
+		{"ReadBytesPerSec", d.ReadBytesPerSec, 16000 * 512 / 2},
+		{"WriteBytesPerSec", d.WriteBytesPerSec, 800 * 512 / 2},
+		{"Utilization", d.Utilization, 50},
+		{"ReadLatencyMs", d.ReadLatencyMs, 2},
+		{"WriteLatencyMs", d.WriteLatencyMs, 5},
+	}
+	for _, tt := range checks {
+		// Allow for the time the test itself takes
+		if math.Abs(tt.got-tt.want) > tt.want*0.01 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDiskStatfsTimeout(t *testing.T) {
+	c := NewDiskCollector(fixtureConfig(t))
+	c.statfsTimeout = 50 * time.Millisecond
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	c.statfs = func(path string) (FilesystemStat, error) {
+		mu.Lock()
+		calls[path]++
+		mu.Unlock()
+		if path == "/mnt/backup disk" {
+			// A hard NFS mount whose server went away
+			<-release
+		}
+		return FilesystemStat{Size: 1 << 20, Free: 1 << 20}, nil
+	}
+	// Errors by mount point, "" for readable ones
+	mounted := func() map[string]string {
+		t.Helper()
+		sample, err := c.Collect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		mounts := make(map[string]string)
+		for _, f := range sample.(DiskSample).Filesystems {
+			mounts[f.MountPoint] = f.Err
+		}
+		return mounts
+	}
+
+	if mounts := mounted(); mounts["/mnt/backup disk"] != errStatfsTimeout.Error() || mounts["/"] != "" {
+		t.Errorf("mounts = %v, want / and the hung mount timed out", mounts)
+	}
+	// Still hung, the mount is listed without another call
+	if mounts := mounted(); mounts["/mnt/backup disk"] != errStatfsTimeout.Error() {
+		t.Errorf("hung mount = %q", mounts["/mnt/backup disk"])
+	}
+	mu.Lock()
+	if calls["/mnt/backup disk"] != 1 || calls["/"] != 2 {
+		t.Errorf("statfs calls = %v, want one on the hung mount", calls)
+	}
+	mu.Unlock()
+
+	close(release)
+	for i := 0; ; i++ {
+		if err, ok := mounted()["/mnt/backup disk"]; ok && err == "" {
+			break
+		}
+		if i == 50 {
+			t.Fatal("mount still unavailable once statfs returned")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Collect(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Collect() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestStatfs(t *testing.T) {
+	f, err := statfs(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Size == 0 || f.Used > f.Size || f.Free > f.Size {
+		t.Errorf("implausible capacity: %+v", f)
+	}
+}
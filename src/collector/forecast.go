@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"math"
+	"time"
+)
+
+// Smoothing factors of the fill-rate forecast. Usage is sampled every few
+// seconds, so both are small enough to ride out temporary files.
+const (
+	forecastAlpha = 0.1  // weight of a new usage reading in the level
+	forecastBeta  = 0.05 // weight of a new slope in the trend
+
+	// forecastWarmup is how long a filesystem must be watched before its
+	// trend is trusted.
+	forecastWarmup = 5 * time.Minute
+)
+
+// holtForecast tracks a series with Holt's linear trend method (double
+// exponential smoothing), adapted to uneven sample intervals. The trend is
+// in units per second.
+type holtForecast struct {
+	level float64
+	trend float64
+
+	first time.Time
+	last  time.Time
+}
+
+// Update adds the reading y taken at t.
+func (h *holtForecast) Update(t time.Time, y float64) {
+	if h.first.IsZero() {
+		h.level = y
+		h.first, h.last = t, t
+		return
+	}
+	dt := t.Sub(h.last).Seconds()
+	if dt <= 0 {
+		return
+	}
+	if h.last == h.first {
+		// Seed the trend with the first slope instead of 0
+		h.trend = (y - h.level) / dt
+	}
+
+	prevLevel := h.level
+	h.level = forecastAlpha*y + (1-forecastAlpha)*(h.level+h.trend*dt)
+	h.trend = forecastBeta*(h.level-prevLevel)/dt + (1-forecastBeta)*h.trend
+	h.last = t
+}
+
+// Rate returns the smoothed change per second.
+func (h *holtForecast) Rate() float64 {
+	return h.trend
+}
+
+// Until returns how long it will take the series to reach limit at the
+// current trend. It reports false while warming up or if the series is not
+// growing.
+func (h *holtForecast) Until(limit float64) (time.Duration, bool) {
+	if h.last.Sub(h.first) < forecastWarmup || h.trend <= 0 {
+		return 0, false
+	}
+	seconds := (limit - h.level) / h.trend
+	if seconds <= 0 {
+		return 0, true
+	}
+	if seconds > math.MaxInt64/float64(time.Second) {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
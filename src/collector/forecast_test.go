@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHoltForecast(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const rate = 1 << 20 // 1 MiB/s
+
+	var h holtForecast
+	for i := 0; i <= 300; i++ {
+		at := start.Add(time.Duration(i) * 2 * time.Second)
+		h.Update(at, float64(i)*2*rate)
+	}
+
+	if math.Abs(h.Rate()-rate) > 1 {
+		t.Errorf("Rate = %v, want %v", h.Rate(), rate)
+	}
+	// 600 MiB used, 3600 MiB more to go at 1 MiB/s
+	d, ok := h.Until(4200 * rate)
+	if !ok || math.Abs(d.Seconds()-3600) > 1 {
+		t.Errorf("Until = %v %v, want 1h", d, ok)
+	}
+}
+
+func TestHoltForecastNoPrediction(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var warming holtForecast
+	warming.Update(start, 0)
+	warming.Update(start.Add(time.Minute), 1000)
+	if _, ok := warming.Until(1e6); ok {
+		t.Errorf("predicted before the warmup was over")
+	}
+
+	var shrinking holtForecast
+	for i := 0; i <= 300; i++ {
+		shrinking.Update(start.Add(time.Duration(i)*2*time.Second), float64(1e6-i*100))
+	}
+	if _, ok := shrinking.Until(2e6); ok {
+		t.Errorf("predicted a shrinking filesystem to fill up")
+	}
+}
+
+func TestDiskForecast(t *testing.T) {
+	c := NewDiskCollector(Config{})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var fs []FilesystemStat
+	for i := 0; i <= 360; i++ {
+		used := uint64(50<<30 + i*10<<20) // 10 MiB per sample
+		fs = []FilesystemStat{
+			{MountPoint: "/var", Size: 100 << 30, Used: used, Free: 100<<30 - used},
+			{MountPoint: "/proc", Pseudo: true},
+		}
+		c.forecast(fs, start.Add(time.Duration(i)*10*time.Second))
+	}
+
+	// 1 MiB/s with 50 GiB - 3600 MiB left
+	want := float64(50<<10 - 3600)
+	got, ok := fs[0].FullIn()
+	if !ok || math.Abs(got.Seconds()-want) > 1 {
+		t.Errorf("/var FullIn = %v %v, want %vs", got, ok, want)
+	}
+	if _, ok := fs[1].FullIn(); ok || len(c.forecasts) != 1 {
+		t.Errorf("pseudo filesystems are forecast")
+	}
+
+	// An unavailable mount keeps its trend but gets no forecast
+	fs = []FilesystemStat{{MountPoint: "/var", Err: "statfs timed out"}}
+	c.forecast(fs, start.Add(time.Hour))
+	if _, ok := fs[0].FullIn(); ok || c.forecasts["/var"] == nil {
+		t.Errorf("unavailable mount forecast %+v, trend kept %v", fs[0], c.forecasts["/var"] != nil)
+	}
+
+	c.forecast(nil, start.Add(time.Hour+time.Second))
+	if len(c.forecasts) != 0 {
+		t.Errorf("unmounted filesystems are not forgotten")
+	}
+}
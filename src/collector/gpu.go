@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrGpuParse is returned when nvidia-smi output cannot be understood.
+var ErrGpuParse = errors.New("unexpected nvidia-smi output")
+
+// ErrNoGpu is returned when the machine has no GPU a collector can read.
+var ErrNoGpu = errors.New("no supported GPU found")
+
+// GpuDevice holds the state of one GPU. Memory figures are in MiB, and are
+// 0 for integrated GPUs that share system memory.
+type GpuDevice struct {
+	Index       int     `json:"index"` // nvidia-smi index, or DRM card number
+	Card        string  `json:"card"`  // unique across both, e.g. "nvidia0" or "card1"
+	UUID        string  `json:"uuid,omitempty"`
+	BusID       string  `json:"bus_id,omitempty"`
+	Name        string  `json:"name"`
+	Driver      string  `json:"driver"`
+	Usage       float64 `json:"usage_percent"`
+	Temp        float64 `json:"temp_celsius"`
+	Fans        string  `json:"fans"`
+	PowerDraw   float64 `json:"power_watts"`
+	PowerLimit  float64 `json:"power_limit_watts,omitempty"`
+	MemoryTotal float64 `json:"memory_total_mib"`
+	MemoryUsed  float64 `json:"memory_used_mib"`
+	MemoryFree  float64 `json:"memory_free_mib"`
+
+	// Only reported by nvidia-smi
+	ClockSM         float64      `json:"clock_sm_mhz,omitempty"`
+	ClockMemory     float64      `json:"clock_memory_mhz,omitempty"`
+	PcieGen         int          `json:"pcie_gen,omitempty"`
+	PcieWidth       int          `json:"pcie_width,omitempty"`
+	EncoderUsage    float64      `json:"encoder_percent"`
+	DecoderUsage    float64      `json:"decoder_percent"`
+	ThrottleReasons []string     `json:"throttle_reasons,omitempty"`
+	Processes       []GpuProcess `json:"processes,omitempty"`
+}
+
+// GpuProcess is a compute process running on a GPU.
+type GpuProcess struct {
+	Pid        int     `json:"pid"`
+	Name       string  `json:"name"`
+	UsedMemory float64 `json:"used_memory_mib"`
+}
+
+type GpuSample struct {
+	Gpus []GpuDevice `json:"gpus"`
+}
+
+// GpuCollector reads NVIDIA GPUs through nvidia-smi, and AMD and Intel GPUs
+// through their DRM driver in sysfs.
+type GpuCollector struct {
+	cfg Config
+
+	mu       sync.Mutex
+	lastIdle map[string]float64 // Intel idle time in ms, keyed by card
+	lastTime time.Time
+}
+
+func NewGpuCollector(cfg Config) *GpuCollector {
+	return &GpuCollector{cfg: cfg, lastIdle: make(map[string]float64)}
+}
+
+func (c *GpuCollector) Name() string { return Gpu }
+
+func (c *GpuCollector) Interval() time.Duration { return time.Second }
+
+func (c *GpuCollector) Collect(ctx context.Context) (Sample, error) {
+	cards := c.cfg.drmCards()
+	now := time.Now()
+
+	var gpus []GpuDevice
+	// Without a DRM tree to go by (e.g. in a container), nvidia-smi is the
+	// only way to find out
+	nvidia := len(cards) == 0
+	for _, card := range cards {
+		switch card.Driver {
+		case "amdgpu", "i915", "xe":
+			gpus = append(gpus, c.drmGpu(card, now))
+		case "nvidia":
+			nvidia = true
+		}
+	}
+	c.mu.Lock()
+	c.lastTime = now
+	c.mu.Unlock()
+
+	if nvidia {
+		nv, err := c.nvidiaGpus(ctx)
+		if err != nil && len(gpus) == 0 {
+			return nil, err
+		}
+		gpus = append(nv, gpus...)
+	}
+	if len(gpus) == 0 {
+		return nil, ErrNoGpu
+	}
+	return GpuSample{Gpus: gpus}, nil
+}
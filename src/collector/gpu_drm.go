@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// drmCardName matches the DRM card directories, leaving out connectors such
+// as "card1-eDP-1" and render nodes.
+var drmCardName = regexp.MustCompile(`^card(\d+)$`)
+
+// drmVendors names GPUs whose driver does not report a product name.
+var drmVendors = map[string]string{
+	"0x1002": "AMD Radeon",
+	"0x8086": "Intel Graphics",
+}
+
+type drmCard struct {
+	Name   string // e.g. "card0"
+	Index  int
+	Driver string // e.g. "amdgpu", "i915", "xe" or "nvidia"
+}
+
+// drmCards lists the GPUs known to the kernel, in card order.
+func (c Config) drmCards() []drmCard {
+	entries, err := os.ReadDir(c.sysPath("class/drm"))
+	if err != nil {
+		return nil
+	}
+	var cards []drmCard
+	for _, e := range entries {
+		m := drmCardName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		driver, err := os.Readlink(c.sysPath("class/drm", e.Name(), "device/driver"))
+		if err != nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		cards = append(cards, drmCard{Name: e.Name(), Index: index, Driver: filepath.Base(driver)})
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].Index < cards[j].Index })
+	return cards
+}
+
+// drmGpu reads an amdgpu, i915 or xe card from sysfs. Usage needs the
+// previous sample for Intel GPUs, and is 0 on the first one.
+func (c *GpuCollector) drmGpu(card drmCard, now time.Time) GpuDevice {
+	dir := c.cfg.sysPath("class/drm", card.Name)
+	device := filepath.Join(dir, "device")
+
+	g := GpuDevice{
+		Index:  card.Index,
+		Card:   card.Name,
+		Name:   readSysString(filepath.Join(device, "product_name")),
+		Driver: card.Driver,
+		Fans:   "N/A",
+	}
+	if link, err := os.Readlink(device); err == nil {
+		g.BusID = filepath.Base(link)
+	}
+	if g.Name == "" {
+		vendor := readSysString(filepath.Join(device, "vendor"))
+		name, ok := drmVendors[vendor]
+		if !ok {
+			name = "GPU"
+		}
+		g.Name = name + " (" + strings.TrimPrefix(vendor, "0x") + ":" + strings.TrimPrefix(readSysString(filepath.Join(device, "device")), "0x") + ")"
+	}
+
+	switch card.Driver {
+	case "amdgpu":
+		g.Usage = float64(readSysUint(filepath.Join(device, "gpu_busy_percent")))
+		g.MemoryTotal = float64(readSysUint(filepath.Join(device, "mem_info_vram_total"))) / (1 << 20)
+		g.MemoryUsed = float64(readSysUint(filepath.Join(device, "mem_info_vram_used"))) / (1 << 20)
+		g.MemoryFree = g.MemoryTotal - g.MemoryUsed
+	case "i915", "xe":
+		if idle, ok := intelIdle(dir); ok {
+			g.Usage = c.intelBusy(card.Name, idle, now)
+		}
+	}
+
+	hwmons, _ := filepath.Glob(filepath.Join(device, "hwmon", "hwmon*"))
+	for _, h := range hwmons {
+		readings := readHwmonDevice(h, filepath.Base(h))
+		// amdgpu calls the die temperature "edge", and other drivers
+		// have a single sensor
+		if r, ok := pickSensor(readings, "", func(r SensorReading) bool {
+			return r.Kind == TempSensor && (r.Label == "edge" || r.Label == r.Sensor)
+		}); ok {
+			g.Temp = r.Value
+		}
+		// Any fan on the card's own hwmon device is the GPU fan
+		if fan, ok := fanSpeed(readings, "", ""); ok {
+			g.Fans = fan
+		}
+		// Microwatts, averaged by older kernels
+		for _, name := range []string{"power1_average", "power1_input"} {
+			if uw := readSysUint(filepath.Join(h, name)); uw > 0 {
+				g.PowerDraw = float64(uw) / 1e6
+				break
+			}
+		}
+	}
+	return g
+}
+
+// intelIdle returns the time in milliseconds the first GT of an Intel card
+// has spent in its idle state (RC6).
+func intelIdle(dir string) (float64, bool) {
+	for _, path := range []string{
+		"gt/gt0/rc6_residency_ms",                   // i915
+		"power/rc6_residency_ms",                    // i915 before multi-GT support
+		"device/tile0/gt0/gtidle/idle_residency_ms", // xe
+	} {
+		if s := readSysString(filepath.Join(dir, path)); s != "" {
+			ms, err := strconv.ParseFloat(s, 64)
+			return ms, err == nil
+		}
+	}
+	return 0, false
+}
+
+// intelBusy turns the idle time of a card into the share of time since the
+// previous sample that it was busy.
+func (c *GpuCollector) intelBusy(card string, idle float64, now time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, ok := c.lastIdle[card]
+	c.lastIdle[card] = idle
+	elapsed := now.Sub(c.lastTime).Seconds() * 1000
+	if !ok || c.lastTime.IsZero() || elapsed <= 0 || idle < last {
+		return 0
+	}
+	return max(0, min(100, 100-(idle-last)/elapsed*100))
+}
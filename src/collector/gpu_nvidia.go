@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// nvidiaQuery lists the --query-gpu fields, in the order parseNvidiaSmi
+// expects them.
+var nvidiaQuery = []string{
+	"index", "uuid", "name", "pci.bus_id",
+	"utilization.gpu", "temperature.gpu", "fan.speed",
+	"memory.total", "memory.used", "memory.free",
+	"power.draw", "power.limit", "clocks.sm", "clocks.mem",
+	"pcie.link.gen.current", "pcie.link.width.current",
+	"utilization.encoder", "utilization.decoder",
+	"clocks_throttle_reasons.active",
+}
+
+// nvidiaThrottleReasons names the bits of clocks_throttle_reasons.active.
+var nvidiaThrottleReasons = []struct {
+	Mask uint64
+	Name string
+}{
+	{0x1, "Idle"},
+	{0x2, "App clocks"},
+	{0x4, "SW power cap"},
+	{0x8, "HW slowdown"},
+	{0x10, "Sync boost"},
+	{0x20, "SW thermal"},
+	{0x40, "HW thermal"},
+	{0x80, "HW power brake"},
+	{0x100, "Display clocks"},
+}
+
+func (c *GpuCollector) nvidiaGpus(ctx context.Context) ([]GpuDevice, error) {
+	// Optimization: Fetch all data in one command
+	cmd := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu="+strings.Join(nvidiaQuery, ","), "--format=csv,noheader,nounits")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	gpus, err := parseNvidiaSmi(string(out))
+	if err != nil {
+		return nil, err
+	}
+
+	// The process list is optional; some drivers do not support it
+	cmd = exec.CommandContext(ctx, "nvidia-smi", "--query-compute-apps=pid,gpu_uuid,used_memory,process_name", "--format=csv,noheader,nounits")
+	if out, err := cmd.Output(); err == nil {
+		apps := parseNvidiaComputeApps(string(out))
+		for i := range gpus {
+			gpus[i].Processes = apps[gpus[i].UUID]
+		}
+	}
+
+	// A fan header wired to the GPU gives RPM instead of a percentage. It
+	// cannot be told apart from other GPUs' fans, so only use it for one.
+	if len(gpus) == 1 {
+		if readings, err := c.cfg.hwmon(); err == nil {
+			if fan, ok := fanSpeed(readings, c.cfg.GpuFanSensor, "gpu"); ok {
+				gpus[0].Fans = fan
+			}
+		}
+	}
+	return gpus, nil
+}
+
+// parseNvidiaSmi parses the output of the --query-gpu command, one line per
+// device. Fields the GPU does not support read "[N/A]" or "[Not
+// Supported]" and are left at 0.
+func parseNvidiaSmi(out string) ([]GpuDevice, error) {
+	var gpus []GpuDevice
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != len(nvidiaQuery) {
+			return nil, ErrGpuParse
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, ErrGpuParse
+		}
+		num := func(i int) float64 {
+			v, _ := strconv.ParseFloat(fields[i], 64)
+			return v
+		}
+		g := GpuDevice{
+			Index:  index,
+			Card:   "nvidia" + fields[0],
+			UUID:   fields[1],
+			Name:   fields[2],
+			BusID:  fields[3],
+			Driver: "nvidia",
+			Fans:   fields[6],
+
+			Usage:       num(4),
+			Temp:        num(5),
+			MemoryTotal: num(7),
+			MemoryUsed:  num(8),
+			MemoryFree:  num(9),
+			PowerDraw:   num(10),
+			PowerLimit:  num(11),
+
+			ClockSM:         num(12),
+			ClockMemory:     num(13),
+			PcieGen:         int(num(14)),
+			PcieWidth:       int(num(15)),
+			EncoderUsage:    num(16),
+			DecoderUsage:    num(17),
+			ThrottleReasons: throttleReasons(fields[18]),
+		}
+		gpus = append(gpus, g)
+	}
+	return gpus, nil
+}
+
+// throttleReasons decodes a clocks_throttle_reasons.active bitmask such as
+// "0x0000000000000004".
+func throttleReasons(mask string) []string {
+	bits, err := strconv.ParseUint(strings.TrimPrefix(mask, "0x"), 16, 64)
+	if err != nil {
+		return nil
+	}
+	var reasons []string
+	for _, r := range nvidiaThrottleReasons {
+		if bits&r.Mask != 0 {
+			reasons = append(reasons, r.Name)
+		}
+	}
+	return reasons
+}
+
+// parseNvidiaComputeApps parses the output of the --query-compute-apps
+// command, keyed by GPU UUID. Lines that cannot be parsed are skipped.
+func parseNvidiaComputeApps(out string) map[string][]GpuProcess {
+	apps := make(map[string][]GpuProcess)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// The name goes last since it may contain commas
+		fields := strings.SplitN(line, ",", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			continue
+		}
+		used, _ := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+		uuid := strings.TrimSpace(fields[1])
+		apps[uuid] = append(apps[uuid], GpuProcess{Pid: pid, Name: strings.TrimSpace(fields[3]), UsedMemory: used})
+	}
+	return apps
+}
@@ -0,0 +1,301 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test/src/internal/fakecmd"
+)
+
+func TestParseNvidiaSmi(t *testing.T) {
+	gpus, err := parseNvidiaSmi(fakecmd.ReadFile(t, "nvidia-smi", "query-gpu.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GpuDevice{
+		{
+			Index: 0, Card: "nvidia0", UUID: "GPU-5f3c1a2e-0b1d-4c6e-9a7f-1e2d3c4b5a69", BusID: "00000000:01:00.0", Name: "NVIDIA GeForce RTX 4090", Driver: "nvidia",
+			Usage: 87, Temp: 71, Fans: "64", PowerDraw: 312.45, PowerLimit: 450,
+			MemoryTotal: 24564, MemoryUsed: 20011, MemoryFree: 4553,
+			ClockSM: 2520, ClockMemory: 10501, PcieGen: 4, PcieWidth: 16, DecoderUsage: 3,
+			ThrottleReasons: []string{"SW power cap"},
+		},
+		{
+			Index: 1, Card: "nvidia1", UUID: "GPU-8d2e4f6a-1c3b-4e5d-8f7a-2b3c4d5e6f70", BusID: "00000000:21:00.0", Name: "NVIDIA RTX A4000", Driver: "nvidia",
+			Temp: 38, Fans: "41", PowerDraw: 14.87, PowerLimit: 140,
+			MemoryTotal: 16376, MemoryUsed: 12, MemoryFree: 16364,
+			ClockSM: 210, ClockMemory: 405, PcieGen: 1, PcieWidth: 16,
+			ThrottleReasons: []string{"Idle"},
+		},
+	}
+	if !reflect.DeepEqual(gpus, want) {
+		t.Errorf("parseNvidiaSmi =\n%+v\nwant\n%+v", gpus, want)
+	}
+
+	for _, bad := range []string{"", "NVIDIA GeForce RTX 4090, 87, 71", "0, GPU-1, A, 1, 2, 3, 4, 5, 6"} {
+		if _, err := parseNvidiaSmi(bad); !errors.Is(err, ErrGpuParse) {
+			t.Errorf("parseNvidiaSmi(%q) error = %v, want ErrGpuParse", bad, err)
+		}
+	}
+}
+
+func TestParseNvidiaSmiUnsupported(t *testing.T) {
+	gpus, err := parseNvidiaSmi(fakecmd.ReadFile(t, "nvidia-smi", "query-gpu-unsupported.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gpus[0]
+	if g.Fans != "[N/A]" || g.PowerDraw != 0 || g.EncoderUsage != 0 || g.ThrottleReasons != nil {
+		t.Errorf("unsupported fields not left empty: %+v", g)
+	}
+	if g.ClockSM != 562 || g.PcieGen != 3 || g.PcieWidth != 8 {
+		t.Errorf("supported fields not parsed: %+v", g)
+	}
+}
+
+func TestThrottleReasons(t *testing.T) {
+	tests := map[string][]string{
+		"0x0000000000000000": nil,
+		"0x0000000000000064": {"SW power cap", "SW thermal", "HW thermal"},
+		"0x00000000000000A0": {"SW thermal", "HW power brake"},
+		"[Not Supported]":    nil,
+	}
+	for mask, want := range tests {
+		if got := throttleReasons(mask); !reflect.DeepEqual(got, want) {
+			t.Errorf("throttleReasons(%q) = %v, want %v", mask, got, want)
+		}
+	}
+}
+
+func TestParseNvidiaComputeApps(t *testing.T) {
+	apps := parseNvidiaComputeApps(fakecmd.ReadFile(t, "nvidia-smi", "compute-apps.csv"))
+	want := map[string][]GpuProcess{
+		"GPU-5f3c1a2e-0b1d-4c6e-9a7f-1e2d3c4b5a69": {
+			{Pid: 100, Name: "/usr/bin/python3", UsedMemory: 18432},
+			{Pid: 4242, Name: "/opt/render, farm/worker", UsedMemory: 1024},
+		},
+		"GPU-8d2e4f6a-1c3b-4e5d-8f7a-2b3c4d5e6f70": {
+			{Pid: 4243, Name: "ollama"},
+		},
+	}
+	if !reflect.DeepEqual(apps, want) {
+		t.Errorf("parseNvidiaComputeApps = %+v, want %+v", apps, want)
+	}
+	if apps := parseNvidiaComputeApps(""); len(apps) != 0 {
+		t.Errorf("no processes parsed as %+v", apps)
+	}
+}
+
+func TestGpuCollectorDrm(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewGpuCollector(cfg)
+
+	// card0 is amdgpu and card1 is i915; no nvidia card, so nvidia-smi is
+	// never run
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpus := sample.(GpuSample).Gpus
+	if len(gpus) != 2 {
+		t.Fatalf("got %d gpus, want 2: %+v", len(gpus), gpus)
+	}
+	amd := GpuDevice{
+		Index: 0, Card: "card0", BusID: "0000:03:00.0", Name: "AMD Radeon RX 6800 XT", Driver: "amdgpu",
+		Usage: 42, Temp: 55, Fans: "1450 RPM", PowerDraw: 120,
+		MemoryTotal: 16368, MemoryUsed: 2048, MemoryFree: 14320,
+	}
+	if !reflect.DeepEqual(gpus[0], amd) {
+		t.Errorf("amdgpu = %+v, want %+v", gpus[0], amd)
+	}
+	intel := GpuDevice{Index: 1, Card: "card1", BusID: "0000:00:02.0", Name: "Intel Graphics (8086:a780)", Driver: "i915", Fans: "N/A"}
+	if !reflect.DeepEqual(gpus[1], intel) {
+		t.Errorf("i915 = %+v, want %+v", gpus[1], intel)
+	}
+
+	// 1.5 s of RC6 over 2 s: busy a quarter of the time
+	c.lastTime = c.lastTime.Add(-2 * time.Second)
+	if err := os.WriteFile(cfg.sysPath("class/drm/card1/gt/gt0/rc6_residency_ms"), []byte("2500\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := sample.(GpuSample).Gpus[1].Usage; math.Abs(usage-25) > 1 {
+		t.Errorf("i915 Usage = %v, want about 25", usage)
+	}
+}
+
+func TestGpuCollectorNoGpu(t *testing.T) {
+	cfg := fixtureConfig(t)
+	// A virtual display adapter is not a GPU we can read
+	for _, card := range []string{"card0", "card1"} {
+		if err := os.RemoveAll(cfg.sysPath("class/drm", card)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(cfg.sysPath("class/drm/card0/device"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../../../bus/pci/drivers/virtio-pci", cfg.sysPath("class/drm/card0/device/driver")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewGpuCollector(cfg).Collect(context.Background()); !errors.Is(err, ErrNoGpu) {
+		t.Errorf("Collect error = %v, want ErrNoGpu", err)
+	}
+}
+
+// nvidiaConfig returns a fixture tree without DRM cards, so the collector
+// falls back to nvidia-smi.
+func nvidiaConfig(t *testing.T) Config {
+	t.Helper()
+	cfg := fixtureConfig(t)
+	if err := os.RemoveAll(cfg.sysPath("class/drm")); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestGpuCollectorNvidiaSmi(t *testing.T) {
+	fakecmd.Install(t, "nvidia-smi",
+		fakecmd.Response{Args: "--query-gpu=", Stdout: fakecmd.ReadFile(t, "nvidia-smi", "query-gpu.csv")},
+		fakecmd.Response{Args: "--query-compute-apps=", Stdout: fakecmd.ReadFile(t, "nvidia-smi", "compute-apps.csv")},
+	)
+
+	sample, err := NewGpuCollector(nvidiaConfig(t)).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpus := sample.(GpuSample).Gpus
+	if len(gpus) != 2 {
+		t.Fatalf("got %d gpus, want 2", len(gpus))
+	}
+	if gpus[0].Name != "NVIDIA GeForce RTX 4090" || len(gpus[0].Processes) != 2 {
+		t.Errorf("gpu 0 = %+v", gpus[0])
+	}
+	// Two GPUs: the motherboard fan is not attributed to either
+	if gpus[1].Name != "NVIDIA RTX A4000" || gpus[1].Fans != "41" || len(gpus[1].Processes) != 1 {
+		t.Errorf("gpu 1 = %+v", gpus[1])
+	}
+}
+
+func TestGpuCollectorNvidiaSmiErrors(t *testing.T) {
+	queryGpu := fakecmd.Response{Args: "--query-gpu=", Stdout: fakecmd.ReadFile(t, "nvidia-smi", "query-gpu-unsupported.csv")}
+
+	tests := []struct {
+		name      string
+		responses []fakecmd.Response // nil leaves nvidia-smi missing
+		drm       bool               // keep the amdgpu and i915 fixture cards
+		check     func(t *testing.T, s GpuSample, err error)
+	}{
+		{
+			name: "missing",
+			check: func(t *testing.T, s GpuSample, err error) {
+				if !errors.Is(err, exec.ErrNotFound) {
+					t.Errorf("error = %v, want exec.ErrNotFound", err)
+				}
+			},
+		},
+		{
+			name: "driver not loaded",
+			responses: []fakecmd.Response{
+				{Stdout: fakecmd.ReadFile(t, "nvidia-smi", "driver-error.txt"), ExitCode: 9},
+			},
+			check: func(t *testing.T, s GpuSample, err error) {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) || exitErr.ExitCode() != 9 {
+					t.Errorf("error = %v, want exit status 9", err)
+				}
+			},
+		},
+		{
+			name: "malformed",
+			responses: []fakecmd.Response{
+				{Stdout: fakecmd.ReadFile(t, "nvidia-smi", "malformed.txt")},
+			},
+			check: func(t *testing.T, s GpuSample, err error) {
+				if !errors.Is(err, ErrGpuParse) {
+					t.Errorf("error = %v, want ErrGpuParse", err)
+				}
+			},
+		},
+		{
+			name:      "compute apps unsupported",
+			responses: []fakecmd.Response{queryGpu, {Args: "--query-compute-apps=", Stderr: "Field \"gpu_uuid\" is not a valid field to query.", ExitCode: 2}},
+			check: func(t *testing.T, s GpuSample, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				// The only GPU gets the RPM of the fan mapped to it
+				if len(s.Gpus) != 1 || s.Gpus[0].Processes != nil || s.Gpus[0].Fans != "1200 RPM" {
+					t.Errorf("gpus = %+v", s.Gpus)
+				}
+			},
+		},
+		{
+			name:      "failure next to other GPUs",
+			responses: []fakecmd.Response{{ExitCode: 9}},
+			drm:       true,
+			check: func(t *testing.T, s GpuSample, err error) {
+				if err != nil || len(s.Gpus) != 2 || s.Gpus[0].Driver != "amdgpu" {
+					t.Errorf("Collect = %+v, %v; want the DRM GPUs only", s.Gpus, err)
+				}
+			},
+		},
+		{
+			name:      "next to other GPUs",
+			responses: []fakecmd.Response{queryGpu, {Args: "--query-compute-apps="}},
+			drm:       true,
+			check: func(t *testing.T, s GpuSample, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				// nvidia0 and card0 share index 0
+				var cards []string
+				for _, g := range s.Gpus {
+					cards = append(cards, g.Card)
+				}
+				if strings.Join(cards, " ") != "nvidia0 card0 card1" {
+					t.Errorf("cards = %v, want one name per GPU", cards)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if tt.drm {
+				cfg = fixtureConfig(t)
+				// Add an NVIDIA card so nvidia-smi is tried too
+				if err := os.MkdirAll(cfg.sysPath("class/drm/card2/device"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink("../../../bus/pci/drivers/nvidia", cfg.sysPath("class/drm/card2/device/driver")); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				cfg = nvidiaConfig(t)
+			}
+			cfg.GpuFanSensor = "nct6775/fan1"
+
+			if tt.responses == nil {
+				fakecmd.EmptyPath(t)
+			} else {
+				fakecmd.Install(t, "nvidia-smi", tt.responses...)
+			}
+			sample, err := NewGpuCollector(cfg).Collect(context.Background())
+			s, _ := sample.(GpuSample)
+			tt.check(t, s, err)
+		})
+	}
+}
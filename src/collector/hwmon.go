@@ -0,0 +1,212 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of hwmon sensors.
+const (
+	TempSensor    = "temp" // °C
+	FanSensor     = "fan"  // RPM
+	VoltageSensor = "in"   // volts
+)
+
+// hwmonInput matches the attribute holding a sensor reading, e.g.
+// "temp1_input".
+var hwmonInput = regexp.MustCompile(`^(temp|fan|in)(\d+)_input$`)
+
+// SensorReading is one hwmon sensor.
+type SensorReading struct {
+	Chip   string  `json:"chip"`   // driver name, e.g. "coretemp"
+	Device string  `json:"device"` // hwmon directory, e.g. "hwmon0"
+	Sensor string  `json:"sensor"` // e.g. "temp1"
+	Kind   string  `json:"kind"`
+	Label  string  `json:"label"` // Sensor when the driver provides none
+	Value  float64 `json:"value"`
+	Crit   float64 `json:"crit,omitempty"` // 0 when unknown
+}
+
+// ID names the reading as "chip/label", the form used to pick sensors in
+// Config.
+func (r SensorReading) ID() string {
+	return r.Chip + "/" + r.Label
+}
+
+// Matches reports whether id names this reading, as "chip/label",
+// "chip/sensor" or "hwmonN/sensor". The last form tells apart chips that
+// share a name, such as several NVMe drives.
+func (r SensorReading) Matches(id string) bool {
+	chip, sensor, ok := strings.Cut(id, "/")
+	if !ok {
+		return false
+	}
+	if chip == r.Device {
+		return sensor == r.Sensor
+	}
+	return chip == r.Chip && (sensor == r.Label || sensor == r.Sensor)
+}
+
+type SensorsSample struct {
+	Sensors []SensorReading `json:"sensors"`
+
+	// CpuPackage is the ID of the reading used as the CPU temperature, empty
+	// if none was found.
+	CpuPackage string `json:"cpu_package,omitempty"`
+}
+
+// SensorsCollector lists every hwmon temperature, fan and voltage sensor.
+type SensorsCollector struct {
+	cfg Config
+}
+
+func NewSensorsCollector(cfg Config) *SensorsCollector {
+	return &SensorsCollector{cfg: cfg}
+}
+
+func (c *SensorsCollector) Name() string { return Sensors }
+
+func (c *SensorsCollector) Interval() time.Duration { return 2 * time.Second }
+
+func (c *SensorsCollector) Collect(ctx context.Context) (Sample, error) {
+	readings, err := c.cfg.hwmon()
+	if err != nil {
+		return nil, err
+	}
+	s := SensorsSample{Sensors: readings}
+	if r, ok := c.cfg.cpuPackage(readings); ok {
+		s.CpuPackage = r.ID()
+	}
+	return s, nil
+}
+
+// hwmon reads every sensor under /sys/class/hwmon, ordered by device and
+// then by sensor.
+func (c Config) hwmon() ([]SensorReading, error) {
+	root := c.sysPath("class/hwmon")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return hwmonIndex(entries[i].Name()) < hwmonIndex(entries[j].Name())
+	})
+
+	var readings []SensorReading
+	for _, e := range entries {
+		dir := filepath.Join(root, e.Name())
+		readings = append(readings, readHwmonDevice(dir, e.Name())...)
+	}
+	return readings, nil
+}
+
+func readHwmonDevice(dir, device string) []SensorReading {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	chip := readSysString(filepath.Join(dir, "name"))
+	if chip == "" {
+		chip = device
+	}
+
+	var readings []SensorReading
+	for _, f := range files {
+		m := hwmonInput.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		kind, sensor := m[1], m[1]+m[2]
+		raw, err := strconv.ParseFloat(readSysString(filepath.Join(dir, f.Name())), 64)
+		if err != nil {
+			// Sensors that are present but disabled fail to read
+			continue
+		}
+		r := SensorReading{
+			Chip:   chip,
+			Device: device,
+			Sensor: sensor,
+			Kind:   kind,
+			Label:  readSysString(filepath.Join(dir, sensor+"_label")),
+			Value:  hwmonValue(kind, raw),
+		}
+		if r.Label == "" {
+			r.Label = sensor
+		}
+		if crit, err := strconv.ParseFloat(readSysString(filepath.Join(dir, sensor+"_crit")), 64); err == nil {
+			r.Crit = hwmonValue(kind, crit)
+		}
+		readings = append(readings, r)
+	}
+	sort.SliceStable(readings, func(i, j int) bool {
+		a, b := readings[i], readings[j]
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind // temp, in, fan
+		}
+		return hwmonIndex(a.Sensor) < hwmonIndex(b.Sensor)
+	})
+	return readings
+}
+
+// hwmonValue converts a raw attribute to the unit of its kind. Temperatures
+// are in millidegrees and voltages in millivolts.
+func hwmonValue(kind string, raw float64) float64 {
+	if kind == FanSensor {
+		return raw
+	}
+	return raw / 1000
+}
+
+// hwmonIndex returns the number at the end of name, e.g. 10 for "hwmon10".
+func hwmonIndex(name string) int {
+	n, _ := strconv.Atoi(strings.TrimLeft(name, "abcdefghijklmnopqrstuvwxyz"))
+	return n
+}
+
+// cpuPackage returns the reading that stands for the CPU temperature.
+func (c Config) cpuPackage(readings []SensorReading) (SensorReading, bool) {
+	return pickSensor(readings, c.CpuTempSensor, func(r SensorReading) bool {
+		if r.Kind != TempSensor {
+			return false
+		}
+		switch r.Chip {
+		case "coretemp":
+			return strings.HasPrefix(r.Label, "Package id")
+		case "k10temp", "zenpower":
+			return r.Label == "Tctl" || r.Label == "Tdie"
+		case "cpu_thermal":
+			return true
+		}
+		return false
+	})
+}
+
+// fanSpeed returns the reading of the fan configured by id, or else of the
+// first fan whose label mentions name, e.g. "CPU Fan" for "cpu".
+func fanSpeed(readings []SensorReading, id, name string) (string, bool) {
+	r, ok := pickSensor(readings, id, func(r SensorReading) bool {
+		return r.Kind == FanSensor && strings.Contains(strings.ToLower(r.Label), name)
+	})
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%.0f RPM", r.Value), true
+}
+
+// pickSensor returns the reading matching id. Only when id is empty does it
+// fall back to the first reading accepted by guess.
+func pickSensor(readings []SensorReading, id string, guess func(SensorReading) bool) (SensorReading, bool) {
+	for _, r := range readings {
+		if id != "" && r.Matches(id) || id == "" && guess(r) {
+			return r, true
+		}
+	}
+	return SensorReading{}, false
+}
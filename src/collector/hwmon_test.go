@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestSensorsCollector(t *testing.T) {
+	c := NewSensorsCollector(fixtureConfig(t))
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(SensorsSample)
+	want := []SensorReading{
+		{Chip: "coretemp", Device: "hwmon0", Sensor: "temp1", Kind: TempSensor, Label: "Package id 0", Value: 52, Crit: 100},
+		{Chip: "coretemp", Device: "hwmon0", Sensor: "temp2", Kind: TempSensor, Label: "Core 0", Value: 50},
+		{Chip: "nct6775", Device: "hwmon1", Sensor: "in0", Kind: VoltageSensor, Label: "Vcore", Value: 1},
+		{Chip: "nct6775", Device: "hwmon1", Sensor: "fan1", Kind: FanSensor, Label: "CPU Fan", Value: 1200},
+		{Chip: "nvme", Device: "hwmon2", Sensor: "temp1", Kind: TempSensor, Label: "Composite", Value: 38.85, Crit: 84.85},
+	}
+	if len(s.Sensors) != len(want) {
+		t.Fatalf("got %d sensors, want %d: %+v", len(s.Sensors), len(want), s.Sensors)
+	}
+	for i := range want {
+		if s.Sensors[i] != want[i] {
+			t.Errorf("sensor %d = %+v, want %+v", i, s.Sensors[i], want[i])
+		}
+	}
+	if s.CpuPackage != "coretemp/Package id 0" {
+		t.Errorf("CpuPackage = %q, want %q", s.CpuPackage, "coretemp/Package id 0")
+	}
+}
+
+func TestSensorMapping(t *testing.T) {
+	cfg := fixtureConfig(t)
+	// A fan with neither a label nor a configured mapping is never guessed
+	if err := os.Remove(cfg.sysPath("class/hwmon/hwmon1/fan1_label")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.CpuTempSensor = "hwmon2/temp1"
+	cfg.GpuFanSensor = "nct6775/fan1"
+	sample, err := NewCpuCollector(cfg).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := sample.(CpuSample); s.Temp != 38.85 || s.FanSpeed != "N/A" {
+		t.Errorf("Temp, FanSpeed = %v, %q; want 38.85, %q", s.Temp, s.FanSpeed, "N/A")
+	}
+
+	readings, err := cfg.hwmon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fan, ok := fanSpeed(readings, cfg.GpuFanSensor, "gpu"); !ok || fan != "1200 RPM" {
+		t.Errorf("gpu fan = %q, %v; want %q", fan, ok, "1200 RPM")
+	}
+
+	// A mapping that matches nothing does not fall back to a guess
+	cfg.CpuTempSensor = "k10temp/Tctl"
+	if r, ok := cfg.cpuPackage(readings); ok {
+		t.Errorf("cpuPackage = %+v, want none", r)
+	}
+}
+
+func TestSensorReadingMatches(t *testing.T) {
+	r := SensorReading{Chip: "nct6775", Device: "hwmon1", Sensor: "fan2", Label: "CPU Fan"}
+	tests := map[string]bool{
+		"nct6775/CPU Fan": true,
+		"nct6775/fan2":    true,
+		"hwmon1/fan2":     true,
+		"hwmon1/CPU Fan":  false,
+		"nct6775/fan1":    false,
+		"nct6775":         false,
+	}
+	for id, want := range tests {
+		if got := r.Matches(id); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
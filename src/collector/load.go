@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// LoadSample describes how busy the scheduler is.
+type LoadSample struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+
+	Running uint64 `json:"procs_running"` // runnable tasks
+	Blocked uint64 `json:"procs_blocked"` // tasks waiting for I/O
+	Tasks   uint64 `json:"tasks"`
+
+	// Rates since the previous sample, 0 on the first one.
+	ContextSwitchesPerSec float64 `json:"context_switches_per_sec"`
+	InterruptsPerSec      float64 `json:"interrupts_per_sec"`
+}
+
+type LoadCollector struct {
+	cfg Config
+
+	mu       sync.Mutex
+	last     procfs.Stat
+	lastTime time.Time
+}
+
+func NewLoadCollector(cfg Config) *LoadCollector {
+	return &LoadCollector{cfg: cfg}
+}
+
+func (c *LoadCollector) Name() string { return Load }
+
+func (c *LoadCollector) Interval() time.Duration { return time.Second }
+
+func (c *LoadCollector) Collect(ctx context.Context) (Sample, error) {
+	fs := c.cfg.procFS()
+	load, err := fs.LoadAvg()
+	if err != nil {
+		return nil, err
+	}
+	stat, err := fs.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	s := LoadSample{
+		Load1:   load.Load1,
+		Load5:   load.Load5,
+		Load15:  load.Load15,
+		Running: stat.ProcsRunning,
+		Blocked: stat.ProcsBlocked,
+		Tasks:   load.Tasks,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !c.lastTime.IsZero() {
+		elapsed := now.Sub(c.lastTime).Seconds()
+		s.ContextSwitchesPerSec = rate(c.last.ContextSwitches, stat.ContextSwitches, elapsed)
+		s.InterruptsPerSec = rate(c.last.Interrupts, stat.Interrupts, elapsed)
+	}
+	c.last = stat
+	c.lastTime = now
+
+	return s, nil
+}
+
+// rate returns the per-second increase of a counter, or 0 if it went
+// backwards.
+func rate(last, cur uint64, seconds float64) float64 {
+	if cur < last || seconds <= 0 {
+		return 0
+	}
+	return float64(cur-last) / seconds
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLoadCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewLoadCollector(cfg)
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(LoadSample)
+	if s.Load1 != 1.52 || s.Load5 != 0.98 || s.Load15 != 0.61 || s.Tasks != 812 {
+		t.Errorf("unexpected load averages: %+v", s)
+	}
+	if s.Running != 2 || s.Blocked != 1 {
+		t.Errorf("Running %d Blocked %d, want 2 and 1", s.Running, s.Blocked)
+	}
+	if s.ContextSwitchesPerSec != 0 || s.InterruptsPerSec != 0 {
+		t.Errorf("first sample has rates: %+v", s)
+	}
+
+	// Pretend the first sample was taken two seconds ago
+	c.lastTime = c.lastTime.Add(-2 * time.Second)
+	writeFixture(t, cfg, "stat", "cpu  1000 0 500 8000 100 0 0 0 0 0\nintr 54000 10 20\nctxt 130000\nprocs_running 5\nprocs_blocked 0\n")
+
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = sample.(LoadSample)
+	if math.Abs(s.ContextSwitchesPerSec-5000) > 50 {
+		t.Errorf("ContextSwitchesPerSec = %v, want about 5000", s.ContextSwitchesPerSec)
+	}
+	if math.Abs(s.InterruptsPerSec-2000) > 20 {
+		t.Errorf("InterruptsPerSec = %v, want about 2000", s.InterruptsPerSec)
+	}
+	if s.Running != 5 || s.Blocked != 0 {
+		t.Errorf("Running %d Blocked %d, want 5 and 0", s.Running, s.Blocked)
+	}
+
+	writeFixture(t, cfg, "loadavg", "garbage\n")
+	if _, err := c.Collect(context.Background()); err == nil {
+		t.Errorf("malformed loadavg was accepted")
+	}
+}
@@ -0,0 +1,178 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// MemorySample holds memory figures in bytes.
+type MemorySample struct {
+	Total     uint64 `json:"total"`
+	Used      uint64 `json:"used"` // excluding buffers and reclaimable cache
+	Free      uint64 `json:"free"`
+	Available uint64 `json:"available"`
+	Buffers   uint64 `json:"buffers"`
+	Cached    uint64 `json:"cached"` // page cache and reclaimable slab
+	Shared    uint64 `json:"shared"`
+	Dirty     uint64 `json:"dirty"`
+	Writeback uint64 `json:"writeback"`
+
+	SwapTotal  uint64 `json:"swap_total"`
+	SwapUsed   uint64 `json:"swap_used"`
+	SwapFree   uint64 `json:"swap_free"`
+	SwapCached uint64 `json:"swap_cached"`
+
+	// Swap traffic since the previous sample, 0 on the first one.
+	SwapInPerSec  float64 `json:"swap_in_bytes_per_sec"`
+	SwapOutPerSec float64 `json:"swap_out_bytes_per_sec"`
+
+	HugePages HugePages `json:"huge_pages"`
+
+	Zram  []ZramDevice `json:"zram,omitempty"`
+	Zswap *ZswapStats  `json:"zswap,omitempty"`
+}
+
+// HugePages describes the persistent huge page pool.
+type HugePages struct {
+	Total    uint64 `json:"total"` // pages
+	Free     uint64 `json:"free"`
+	Reserved uint64 `json:"reserved"`
+	Surplus  uint64 `json:"surplus"`
+	PageSize uint64 `json:"page_size"` // bytes
+}
+
+// ZramDevice is a compressed RAM block device, usually used for swap.
+type ZramDevice struct {
+	Name       string `json:"name"`
+	Algorithm  string `json:"algorithm"`
+	DiskSize   uint64 `json:"disk_size"`
+	OrigData   uint64 `json:"orig_data_size"`  // uncompressed size of the stored data
+	Compressed uint64 `json:"compr_data_size"` // compressed size of the stored data
+	MemUsed    uint64 `json:"mem_used_total"`  // memory used, including overhead
+}
+
+// ZswapStats describes the compressed swap cache.
+type ZswapStats struct {
+	Enabled  bool   `json:"enabled"`
+	PoolSize uint64 `json:"pool_size"`    // compressed size
+	Stored   uint64 `json:"stored_bytes"` // size of the pages stored in the pool
+}
+
+type MemoryCollector struct {
+	cfg Config
+
+	mu       sync.Mutex
+	last     procfs.VMStat
+	lastTime time.Time
+}
+
+func NewMemoryCollector(cfg Config) *MemoryCollector {
+	return &MemoryCollector{cfg: cfg}
+}
+
+func (c *MemoryCollector) Name() string { return Memory }
+
+func (c *MemoryCollector) Interval() time.Duration { return time.Second }
+
+func (c *MemoryCollector) Collect(ctx context.Context) (Sample, error) {
+	fs := c.cfg.procFS()
+	mi, err := fs.Meminfo()
+	if err != nil {
+		return nil, err
+	}
+
+	cached := mi.Cached + mi.SReclaimable
+	s := MemorySample{
+		Total:     mi.MemTotal,
+		Free:      mi.MemFree,
+		Available: mi.MemAvailable,
+		Buffers:   mi.Buffers,
+		Cached:    cached,
+		Shared:    mi.Shmem,
+		Dirty:     mi.Dirty,
+		Writeback: mi.Writeback,
+
+		SwapTotal:  mi.SwapTotal,
+		SwapFree:   mi.SwapFree,
+		SwapCached: mi.SwapCached,
+
+		HugePages: HugePages{
+			Total:    mi.HugePagesTotal,
+			Free:     mi.HugePagesFree,
+			Reserved: mi.HugePagesRsvd,
+			Surplus:  mi.HugePagesSurp,
+			PageSize: mi.HugePageSize,
+		},
+
+		Zram:  c.zram(),
+		Zswap: c.zswap(mi),
+	}
+	// Same definition as free(1)
+	if used := mi.MemFree + mi.Buffers + cached; used < mi.MemTotal {
+		s.Used = mi.MemTotal - used
+	}
+	if mi.SwapFree < mi.SwapTotal {
+		s.SwapUsed = mi.SwapTotal - mi.SwapFree
+	}
+
+	if vm, err := fs.VMStat(); err == nil {
+		c.swapRates(&s, vm)
+	}
+	return s, nil
+}
+
+func (c *MemoryCollector) swapRates(s *MemorySample, vm procfs.VMStat) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !c.lastTime.IsZero() {
+		elapsed := now.Sub(c.lastTime).Seconds()
+		page := float64(os.Getpagesize())
+		s.SwapInPerSec = rate(c.last.PswpIn, vm.PswpIn, elapsed) * page
+		s.SwapOutPerSec = rate(c.last.PswpOut, vm.PswpOut, elapsed) * page
+	}
+	c.last = vm
+	c.lastTime = now
+}
+
+func (c *MemoryCollector) zram() []ZramDevice {
+	dirs, _ := filepath.Glob(c.cfg.sysPath("block", "zram*"))
+
+	var devices []ZramDevice
+	for _, dir := range dirs {
+		// orig_data_size compr_data_size mem_used_total mem_limit ...
+		fields := strings.Fields(readSysString(filepath.Join(dir, "mm_stat")))
+		if len(fields) < 3 {
+			continue
+		}
+		d := ZramDevice{
+			Name:       filepath.Base(dir),
+			Algorithm:  currentChoice(readSysString(filepath.Join(dir, "comp_algorithm"))),
+			DiskSize:   readSysUint(filepath.Join(dir, "disksize")),
+			OrigData:   parseSysUint(fields[0]),
+			Compressed: parseSysUint(fields[1]),
+			MemUsed:    parseSysUint(fields[2]),
+		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+func (c *MemoryCollector) zswap(mi procfs.Meminfo) *ZswapStats {
+	enabled := readSysString(c.cfg.sysPath("module/zswap/parameters/enabled"))
+	if enabled == "" {
+		return nil
+	}
+	return &ZswapStats{
+		Enabled:  enabled == "Y",
+		PoolSize: mi.Zswap,
+		Stored:   mi.Zswapped,
+	}
+}
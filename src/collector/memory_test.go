@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemoryCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewMemoryCollector(cfg)
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(MemorySample)
+	if s.Total != 8000000*1024 {
+		t.Errorf("Total = %d, want %d", s.Total, 8000000*1024)
+	}
+	if s.Free != 2000000*1024 {
+		t.Errorf("Free = %d, want %d", s.Free, 2000000*1024)
+	}
+	// Total - Free - Buffers - Cached - SReclaimable
+	if s.Used != 3000000*1024 || s.Cached != 2800000*1024 || s.Buffers != 200000*1024 {
+		t.Errorf("Used %d Cached %d Buffers %d", s.Used, s.Cached, s.Buffers)
+	}
+	if s.SwapTotal != 4000000*1024 || s.SwapUsed != 1000000*1024 || s.SwapCached != 20000*1024 {
+		t.Errorf("unexpected swap: total %d used %d cached %d", s.SwapTotal, s.SwapUsed, s.SwapCached)
+	}
+	if want := (HugePages{Total: 16, Free: 8, Reserved: 2, PageSize: 2048 * 1024}); s.HugePages != want {
+		t.Errorf("HugePages = %+v, want %+v", s.HugePages, want)
+	}
+	if s.SwapInPerSec != 0 || s.SwapOutPerSec != 0 {
+		t.Errorf("first sample has swap rates")
+	}
+
+	if len(s.Zram) != 1 {
+		t.Fatalf("got %d zram devices, want 1", len(s.Zram))
+	}
+	want := ZramDevice{Name: "zram0", Algorithm: "zstd", DiskSize: 2 << 30, OrigData: 100 << 20, Compressed: 25 << 20, MemUsed: 27 << 20}
+	if s.Zram[0] != want {
+		t.Errorf("Zram = %+v, want %+v", s.Zram[0], want)
+	}
+	if s.Zswap == nil || !s.Zswap.Enabled || s.Zswap.PoolSize != 50000*1024 || s.Zswap.Stored != 150000*1024 {
+		t.Errorf("Zswap = %+v", s.Zswap)
+	}
+
+	// 500 pages in and 1000 out over two seconds
+	c.lastTime = c.lastTime.Add(-2 * time.Second)
+	writeFixture(t, cfg, "vmstat", "pswpin 1500\npswpout 3000\n")
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = sample.(MemorySample)
+	page := float64(os.Getpagesize())
+	if math.Abs(s.SwapInPerSec-250*page) > 5*page || math.Abs(s.SwapOutPerSec-500*page) > 5*page {
+		t.Errorf("swap rates %v in %v out, want about %v and %v", s.SwapInPerSec, s.SwapOutPerSec, 250*page, 500*page)
+	}
+}
+
+func TestMemoryWithoutZram(t *testing.T) {
+	cfg := fixtureConfig(t)
+	if err := os.RemoveAll(cfg.sysPath("block")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(cfg.sysPath("module")); err != nil {
+		t.Fatal(err)
+	}
+
+	sample, err := NewMemoryCollector(cfg).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := sample.(MemorySample); s.Zram != nil || s.Zswap != nil {
+		t.Errorf("got zram %+v zswap %+v, want neither", s.Zram, s.Zswap)
+	}
+}
@@ -0,0 +1,198 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// ProcessNetStat is the network traffic of one process, or of every process
+// of a container's network namespace. Rates cover the time since the
+// previous sample and are 0 on the first.
+type ProcessNetStat struct {
+	Pid  int    `json:"pid"` // the lowest pid for a namespace
+	Name string `json:"name"`
+
+	// Namespace is set for processes outside our network namespace, whose
+	// traffic is that of all the interfaces of their namespace.
+	Namespace string `json:"namespace,omitempty"`
+	Processes int    `json:"processes"` // processes sharing the traffic
+
+	Connections     int     `json:"connections"` // open TCP sockets, in our namespace only
+	RecvBytesPerSec float64 `json:"recv_bytes_per_sec"`
+	SentBytesPerSec float64 `json:"sent_bytes_per_sec"`
+}
+
+type NetworkProcessesSample struct {
+	// Processes with TCP sockets or in another network namespace, busiest
+	// first.
+	Processes []ProcessNetStat `json:"processes"`
+
+	// SocketErr explains why per-socket counters are missing, in which
+	// case only namespaces are accounted for.
+	SocketErr string `json:"socket_err,omitempty"`
+}
+
+// socketBytes holds the bytes a TCP socket has moved.
+type socketBytes struct {
+	Recv uint64
+	Sent uint64
+}
+
+// socketBytesFunc returns the counters of the TCP sockets of our network
+// namespace, keyed by inode.
+type socketBytesFunc func() (map[uint64]socketBytes, error)
+
+// NetworkProcessesCollector attributes network traffic to processes. Within
+// our network namespace it sums the TCP counters of the sockets each
+// process holds, so UDP is not accounted for. Processes of other
+// namespaces, such as containers, get the interface counters of their
+// namespace from /proc/[pid]/net/dev.
+//
+// Sockets of other users' processes are only found when running as root.
+type NetworkProcessesCollector struct {
+	fs      procfs.FS
+	sockets socketBytesFunc
+
+	mu         sync.Mutex
+	lastSocket map[uint64]socketBytes
+	lastNs     map[string]socketBytes
+	lastTime   time.Time
+}
+
+func NewNetworkProcessesCollector(cfg Config) *NetworkProcessesCollector {
+	return &NetworkProcessesCollector{
+		fs:         cfg.procFS(),
+		sockets:    tcpSocketBytes,
+		lastSocket: make(map[uint64]socketBytes),
+		lastNs:     make(map[string]socketBytes),
+	}
+}
+
+func (c *NetworkProcessesCollector) Name() string { return NetworkProcesses }
+
+func (c *NetworkProcessesCollector) Interval() time.Duration { return 2 * time.Second }
+
+func (c *NetworkProcessesCollector) Collect(ctx context.Context) (Sample, error) {
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return nil, err
+	}
+	s := NetworkProcessesSample{}
+	sockets, err := c.sockets()
+	if err != nil {
+		s.SocketErr = err.Error()
+	}
+	own, _ := c.fs.SelfNetNamespace()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	first := c.lastTime.IsZero()
+
+	namespaces := make(map[string][]procfs.Proc)
+	var nsOrder []string
+	claimed := make(map[uint64]bool) // sockets shared after a fork count once
+	for _, p := range procs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		ns, err := p.NetNamespace()
+		if err != nil {
+			continue
+		}
+		if ns != own && own != "" {
+			if _, ok := namespaces[ns]; !ok {
+				nsOrder = append(nsOrder, ns)
+			}
+			namespaces[ns] = append(namespaces[ns], p)
+			continue
+		}
+
+		inodes, err := p.SocketInodes()
+		if err != nil {
+			continue
+		}
+		stat := ProcessNetStat{Pid: p.PID, Processes: 1}
+		var moved socketBytes
+		for _, inode := range inodes {
+			cur, ok := sockets[inode]
+			if !ok || claimed[inode] {
+				continue
+			}
+			claimed[inode] = true
+			stat.Connections++
+			// Sockets opened since the last sample moved all their bytes
+			// in between
+			last := c.lastSocket[inode]
+			if cur.Recv >= last.Recv && cur.Sent >= last.Sent {
+				moved.Recv += cur.Recv - last.Recv
+				moved.Sent += cur.Sent - last.Sent
+			}
+		}
+		if stat.Connections == 0 {
+			continue
+		}
+		if !first && elapsed > 0 {
+			stat.RecvBytesPerSec = float64(moved.Recv) / elapsed
+			stat.SentBytesPerSec = float64(moved.Sent) / elapsed
+		}
+		if ps, err := p.Stat(); err == nil {
+			stat.Name = ps.Comm
+		}
+		s.Processes = append(s.Processes, stat)
+	}
+
+	lastNs := make(map[string]socketBytes, len(namespaces))
+	for _, ns := range nsOrder {
+		members := namespaces[ns]
+		cur, ok := namespaceBytes(members[0])
+		if !ok {
+			continue
+		}
+		lastNs[ns] = cur
+		stat := ProcessNetStat{Pid: members[0].PID, Namespace: ns, Processes: len(members)}
+		if last, ok := c.lastNs[ns]; ok && !first {
+			stat.RecvBytesPerSec = rate(last.Recv, cur.Recv, elapsed)
+			stat.SentBytesPerSec = rate(last.Sent, cur.Sent, elapsed)
+		}
+		if ps, err := members[0].Stat(); err == nil {
+			stat.Name = ps.Comm
+		}
+		s.Processes = append(s.Processes, stat)
+	}
+
+	sort.SliceStable(s.Processes, func(i, j int) bool {
+		a, b := s.Processes[i], s.Processes[j]
+		return a.RecvBytesPerSec+a.SentBytesPerSec > b.RecvBytesPerSec+b.SentBytesPerSec
+	})
+
+	if sockets != nil {
+		c.lastSocket = sockets
+	}
+	c.lastNs = lastNs
+	c.lastTime = now
+	return s, nil
+}
+
+// namespaceBytes sums the traffic of the interfaces of the namespace of p,
+// leaving out loopback.
+func namespaceBytes(p procfs.Proc) (socketBytes, bool) {
+	lines, err := p.NetDev()
+	if err != nil {
+		return socketBytes{}, false
+	}
+	var total socketBytes
+	for _, l := range lines {
+		if l.Name != "lo" {
+			total.Recv += l.RxBytes
+			total.Sent += l.TxBytes
+		}
+	}
+	return total, true
+}
@@ -0,0 +1,86 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNetworkProcessesCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewNetworkProcessesCollector(cfg)
+	sockets := map[uint64]socketBytes{
+		1001: {},                        // sshd, listening
+		1005: {Recv: 5000, Sent: 20000}, // sshd session
+		4242: {Recv: 1 << 20, Sent: 1 << 16},
+		9999: {Recv: 1 << 30}, // not open in any process we can read
+	}
+	c.sockets = func() (map[uint64]socketBytes, error) { return sockets, nil }
+
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Over 2 seconds, the browser receives 4 MiB, sshd sends 2000 bytes
+	// and the nginx container sends 1 MB
+	c.lastTime = c.lastTime.Add(-2 * time.Second)
+	sockets = map[uint64]socketBytes{
+		1001: {},
+		1005: {Recv: 5000, Sent: 22000},
+		4242: {Recv: 5 << 20, Sent: 1 << 16},
+	}
+	writeFixture(t, cfg, "400/net/dev", `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    9000      90    0    0    0     0          0         0     9000      90    0    0    0     0       0          0
+  eth0: 1000000    900    0    0    0     0          0         0  5000000    4000    0    0    0     0       0          0
+`)
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(NetworkProcessesSample)
+
+	want := []struct {
+		pid         int
+		name        string
+		namespace   string
+		processes   int
+		connections int
+		recv, sent  float64
+	}{
+		{100, "Web Content", "", 1, 1, 2 << 20, 0},
+		{400, "nginx", "net:[4026532451]", 2, 0, 0, 500000},
+		{300, "sshd", "", 1, 2, 0, 1000},
+	}
+	if len(s.Processes) != len(want) {
+		t.Fatalf("got %d processes, want %d: %+v", len(s.Processes), len(want), s.Processes)
+	}
+	for i, w := range want {
+		p := s.Processes[i]
+		if p.Pid != w.pid || p.Name != w.name || p.Namespace != w.namespace || p.Processes != w.processes || p.Connections != w.connections {
+			t.Errorf("process %d = %+v, want %+v", i, p, w)
+		}
+		if math.Abs(p.RecvBytesPerSec-w.recv) > w.recv/50 || math.Abs(p.SentBytesPerSec-w.sent) > w.sent/50 {
+			t.Errorf("%s: rx, tx = %v, %v B/s; want %v, %v", w.name, p.RecvBytesPerSec, p.SentBytesPerSec, w.recv, w.sent)
+		}
+	}
+	if s.SocketErr != "" {
+		t.Errorf("SocketErr = %q", s.SocketErr)
+	}
+}
+
+func TestNetworkProcessesWithoutSockDiag(t *testing.T) {
+	c := NewNetworkProcessesCollector(fixtureConfig(t))
+	c.sockets = func() (map[uint64]socketBytes, error) { return nil, errors.New("sock_diag: permission denied") }
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(NetworkProcessesSample)
+	if s.SocketErr != "sock_diag: permission denied" || len(s.Processes) != 1 || s.Processes[0].Name != "nginx" {
+		t.Errorf("got %+v, want only the nginx namespace", s)
+	}
+}
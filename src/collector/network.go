@@ -0,0 +1,227 @@
+package collector
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+// InterfaceStat describes one network interface. Counters are cumulative;
+// rates cover the time since the previous sample and are 0 on the first.
+type InterfaceStat struct {
+	Name    string   `json:"name"`
+	State   string   `json:"state"` // operstate, e.g. "up", "down" or "unknown"
+	MTU     int      `json:"mtu"`
+	MAC     string   `json:"mac"`
+	Addrs   []string `json:"addrs"`   // IPv4 and IPv6, in CIDR notation
+	Virtual bool     `json:"virtual"` // loopback, bridges, veth pairs, tunnels...
+	WiFi    bool     `json:"wifi"`
+
+	BytesRecv   uint64 `json:"bytes_recv"`
+	BytesSent   uint64 `json:"bytes_sent"`
+	PacketsRecv uint64 `json:"packets_recv"`
+	PacketsSent uint64 `json:"packets_sent"`
+	ErrorsIn    uint64 `json:"errors_in"`
+	ErrorsOut   uint64 `json:"errors_out"`
+	DropsIn     uint64 `json:"drops_in"`
+	DropsOut    uint64 `json:"drops_out"`
+
+	RecvBytesPerSec   float64 `json:"recv_bytes_per_sec"`
+	SentBytesPerSec   float64 `json:"sent_bytes_per_sec"`
+	RecvPacketsPerSec float64 `json:"recv_packets_per_sec"`
+	SentPacketsPerSec float64 `json:"sent_packets_per_sec"`
+}
+
+// InterfaceInfo describes the interface used for the default route, the
+// IPv4 one unless there is only an IPv6 default route.
+type InterfaceInfo struct {
+	Name         string `json:"name"`      // empty without a default route
+	NetType      string `json:"type"`      // "Wired" or "WiFi"
+	WifiBand     string `json:"wifi_band"` // see WirelessLink.Band
+	Ipv6Disabled bool   `json:"ipv6_disabled"`
+
+	IPv4 Route `json:"ipv4"`
+	IPv6 Route `json:"ipv6"`
+}
+
+type NetworkSample struct {
+	Timestamp  time.Time       `json:"timestamp"`
+	Default    InterfaceInfo   `json:"default"`
+	Interfaces []InterfaceStat `json:"interfaces"`
+}
+
+// Counters returns the named interface.
+func (s NetworkSample) Counters(name string) (InterfaceStat, bool) {
+	for _, c := range s.Interfaces {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return InterfaceStat{}, false
+}
+
+// addrsFunc returns the addresses of the named interface.
+type addrsFunc func(name string) []string
+
+type NetworkCollector struct {
+	cfg   Config
+	addrs addrsFunc
+	link  wirelessLinkFunc // for the band of a WiFi default interface
+
+	// The default routes info was detected for, re-detected when they
+	// change
+	routes   defaultRoutes
+	detected bool
+	info     InterfaceInfo
+
+	mu       sync.Mutex
+	last     map[string]InterfaceStat
+	lastTime time.Time
+}
+
+func NewNetworkCollector(cfg Config) *NetworkCollector {
+	return &NetworkCollector{
+		cfg:   cfg,
+		addrs: interfaceAddrs,
+		link:  nl80211Link,
+		last:  make(map[string]InterfaceStat),
+	}
+}
+
+func (c *NetworkCollector) Name() string { return Network }
+
+func (c *NetworkCollector) Interval() time.Duration { return time.Second }
+
+func (c *NetworkCollector) Collect(ctx context.Context) (Sample, error) {
+	if routes := c.cfg.defaultRoutes(); !c.detected || routes != c.routes {
+		c.info = c.detectInterfaceInfo(routes)
+		c.routes, c.detected = routes, true
+	}
+
+	counters, err := psnet.IOCountersWithContext(c.cfg.context(ctx), true)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NetworkSample{
+		Timestamp:  time.Now(),
+		Default:    c.info,
+		Interfaces: make([]InterfaceStat, 0, len(counters)),
+	}
+	for _, counter := range counters {
+		s.Interfaces = append(s.Interfaces, c.interfaceStat(counter))
+	}
+	c.rates(s.Interfaces, s.Timestamp)
+	return s, nil
+}
+
+func (c *NetworkCollector) interfaceStat(counter psnet.IOCountersStat) InterfaceStat {
+	dir := c.cfg.sysPath("class/net", counter.Name)
+	i := InterfaceStat{
+		Name:  counter.Name,
+		State: readSysString(filepath.Join(dir, "operstate")),
+		MAC:   readSysString(filepath.Join(dir, "address")),
+		Addrs: c.addrs(counter.Name),
+
+		BytesRecv:   counter.BytesRecv,
+		BytesSent:   counter.BytesSent,
+		PacketsRecv: counter.PacketsRecv,
+		PacketsSent: counter.PacketsSent,
+		ErrorsIn:    counter.Errin,
+		ErrorsOut:   counter.Errout,
+		DropsIn:     counter.Dropin,
+		DropsOut:    counter.Dropout,
+	}
+	i.MTU, _ = strconv.Atoi(readSysString(filepath.Join(dir, "mtu")))
+	// Only interfaces backed by hardware link to a device
+	if _, err := os.Lstat(filepath.Join(dir, "device")); err != nil {
+		i.Virtual = true
+	}
+	i.WiFi = isWireless(dir)
+	return i
+}
+
+func (c *NetworkCollector) rates(interfaces []InterfaceStat, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := now.Sub(c.lastTime).Seconds()
+	first := c.lastTime.IsZero()
+	last := c.last
+	c.last = make(map[string]InterfaceStat, len(interfaces))
+	for idx := range interfaces {
+		i := &interfaces[idx]
+		if l, ok := last[i.Name]; ok && !first {
+			i.RecvBytesPerSec = rate(l.BytesRecv, i.BytesRecv, elapsed)
+			i.SentBytesPerSec = rate(l.BytesSent, i.BytesSent, elapsed)
+			i.RecvPacketsPerSec = rate(l.PacketsRecv, i.PacketsRecv, elapsed)
+			i.SentPacketsPerSec = rate(l.PacketsSent, i.PacketsSent, elapsed)
+		}
+		c.last[i.Name] = *i
+	}
+	c.lastTime = now
+}
+
+// isWireless reports whether the interface in dir is a WiFi interface.
+func isWireless(dir string) bool {
+	for _, name := range []string{"wireless", "phy80211"} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func interfaceAddrs(name string) []string {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+	out := make([]string, len(addrs))
+	for i, a := range addrs {
+		out[i] = a.String()
+	}
+	return out
+}
+
+func (c *NetworkCollector) detectInterfaceInfo(routes defaultRoutes) InterfaceInfo {
+	info := InterfaceInfo{IPv4: routes.IPv4, IPv6: routes.IPv6}
+
+	// 1. Find default interface, preferring IPv4
+	info.Name = routes.IPv4.Interface
+	if info.Name == "" {
+		info.Name = routes.IPv6.Interface
+	}
+
+	// 2. Check if wireless
+	if info.Name == "" {
+		info.NetType = "None"
+	} else if isWireless(c.cfg.sysPath("class/net", info.Name)) {
+		info.NetType = "WiFi"
+		if l, err := c.link(info.Name); err == nil {
+			info.WifiBand = l.Band
+		}
+	} else {
+		info.NetType = "Wired"
+	}
+
+	// 3. Check IPv6
+	// cat /proc/sys/net/ipv6/conf/all/disable_ipv6
+	ipv6Out, err := os.ReadFile(c.cfg.procPath("sys/net/ipv6/conf/all/disable_ipv6"))
+	if err == nil {
+		info.Ipv6Disabled = strings.TrimSpace(string(ipv6Out)) == "1"
+	}
+
+	return info
+}
@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNetworkCollector(t *testing.T) {
+	sample, err := NewNetworkCollector(fixtureConfig(t)).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(NetworkSample)
+
+	tests := []struct {
+		name      string
+		bytesRecv uint64
+		bytesSent uint64
+	}{
+		{"lo", 5000, 5000},
+		{"eth0", 1048576, 524288},
+	}
+	for _, tt := range tests {
+		c, ok := s.Counters(tt.name)
+		if !ok {
+			t.Errorf("no counters for %s", tt.name)
+			continue
+		}
+		if c.BytesRecv != tt.bytesRecv || c.BytesSent != tt.bytesSent {
+			t.Errorf("%s: got rx %d tx %d, want rx %d tx %d", tt.name, c.BytesRecv, c.BytesSent, tt.bytesRecv, tt.bytesSent)
+		}
+	}
+	if s.Default.Ipv6Disabled {
+		t.Errorf("Ipv6Disabled = true, want false")
+	}
+}
+
+func TestNetworkInterfaces(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewNetworkCollector(cfg)
+	c.addrs = func(name string) []string {
+		if name == "eth0" {
+			return []string{"192.168.1.20/24", "fe80::5054:ff:fe12:3456/64"}
+		}
+		return nil
+	}
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(NetworkSample)
+	if len(s.Interfaces) != 5 {
+		t.Fatalf("got %d interfaces, want 5", len(s.Interfaces))
+	}
+
+	eth0, _ := s.Counters("eth0")
+	want := InterfaceStat{
+		Name: "eth0", State: "up", MTU: 1500, MAC: "52:54:00:12:34:56",
+		Addrs:     []string{"192.168.1.20/24", "fe80::5054:ff:fe12:3456/64"},
+		BytesRecv: 1048576, BytesSent: 524288, PacketsRecv: 1000, PacketsSent: 800,
+		ErrorsIn: 1, DropsIn: 2, DropsOut: 1,
+	}
+	if !reflect.DeepEqual(eth0, want) {
+		t.Errorf("eth0 = %+v, want %+v", eth0, want)
+	}
+
+	kinds := map[string][2]bool{ // virtual, wifi
+		"lo":          {true, false},
+		"eth0":        {false, false},
+		"wlan0":       {false, true},
+		"docker0":     {true, false},
+		"veth3f2a1b0": {true, false},
+	}
+	for name, kind := range kinds {
+		i, _ := s.Counters(name)
+		if i.Virtual != kind[0] || i.WiFi != kind[1] {
+			t.Errorf("%s: Virtual, WiFi = %v, %v; want %v, %v", name, i.Virtual, i.WiFi, kind[0], kind[1])
+		}
+	}
+
+	// 2 MiB and 200 packets in, 100 packets out over 2 seconds
+	c.lastTime = c.lastTime.Add(-2 * time.Second)
+	writeFixture(t, cfg, "net/dev", `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+  eth0: 3145728    1200    1    2    0     0          0         0   524288     900    0    1    0     0       0          0
+`)
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	eth0, _ = sample.(NetworkSample).Counters("eth0")
+	if math.Abs(eth0.RecvBytesPerSec-1<<20) > 1<<14 || eth0.SentBytesPerSec != 0 {
+		t.Errorf("eth0 rx, tx = %v, %v B/s; want 1 MiB/s, 0", eth0.RecvBytesPerSec, eth0.SentBytesPerSec)
+	}
+	if math.Abs(eth0.RecvPacketsPerSec-100) > 2 || math.Abs(eth0.SentPacketsPerSec-50) > 1 {
+		t.Errorf("eth0 packets rx, tx = %v, %v/s; want 100, 50", eth0.RecvPacketsPerSec, eth0.SentPacketsPerSec)
+	}
+}
+
+func TestDefaultRoutes(t *testing.T) {
+	routes := fixtureConfig(t).defaultRoutes()
+	want := defaultRoutes{
+		IPv4: Route{Interface: "eth0", Gateway: "10.0.0.1", Metric: 100},
+		IPv6: Route{Interface: "wlan0", Gateway: "fe80::1", Metric: 600},
+	}
+	if routes != want {
+		t.Errorf("defaultRoutes() = %+v, want %+v", routes, want)
+	}
+
+	tests := []struct {
+		name   string
+		fields string
+		ok     bool
+	}{
+		{"ipv4 on-link", "ppp0 00000000 00000000 0001 0 0 0 00000000 0 0 0", true},
+		{"ipv4 not default", "eth0 0000000A 00000000 0001 0 0 100 00FFFFFF 0 0 0", false},
+		{"ipv4 down", "eth0 00000000 0100000A 0002 0 0 100 00000000 0 0 0", false},
+		{"ipv4 header", "Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT", false},
+		{"ipv6 unreachable", "00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200 lo", false},
+		{"ipv6 not default", "fd000000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000258 00000001 00000000 00000001 wlan0", false},
+		{"ipv6 truncated", "00000000000000000000000000000000 00", false},
+	}
+	for _, tt := range tests {
+		parse := parseIPv4Route
+		if strings.HasPrefix(tt.name, "ipv6") {
+			parse = parseIPv6Route
+		}
+		r, ok := parse(strings.Fields(tt.fields))
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+		if ok && r.Gateway != "" {
+			t.Errorf("%s: Gateway = %q, want none", tt.name, r.Gateway)
+		}
+	}
+}
+
+func TestNetworkRouteChange(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewNetworkCollector(cfg)
+	c.link = func(name string) (WirelessLink, error) {
+		return WirelessLink{Interface: name, Connected: true, Frequency: 5180, Band: "5GHz"}, nil
+	}
+
+	collect := func() InterfaceInfo {
+		t.Helper()
+		sample, err := c.Collect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sample.(NetworkSample).Default
+	}
+
+	if info := collect(); info.Name != "eth0" || info.NetType != "Wired" || info.IPv6.Interface != "wlan0" {
+		t.Errorf("Default = %+v, want eth0, wired, with IPv6 through wlan0", info)
+	}
+
+	// Unplugging the cable leaves the WiFi default route
+	writeFixture(t, cfg, "net/route", `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+wlan0	00000000	0101A8C0	0003	0	0	600	00000000	0	0	0
+`)
+	info := collect()
+	want := InterfaceInfo{
+		Name: "wlan0", NetType: "WiFi", WifiBand: "5GHz",
+		IPv4: Route{Interface: "wlan0", Gateway: "192.168.1.1", Metric: 600},
+		IPv6: Route{Interface: "wlan0", Gateway: "fe80::1", Metric: 600},
+	}
+	if info != want {
+		t.Errorf("Default = %+v, want %+v", info, want)
+	}
+
+	// Without any default route there is no default interface
+	writeFixture(t, cfg, "net/route", "")
+	writeFixture(t, cfg, "net/ipv6_route", "")
+	if info := collect(); info.Name != "" || info.NetType != "None" {
+		t.Errorf("Default = %+v, want none", info)
+	}
+}
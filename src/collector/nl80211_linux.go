@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// Constants of <linux/genetlink.h> and <linux/nl80211.h>.
+const (
+	genlIdCtrl         = 0x10
+	genlHdrLen         = 4 // struct genlmsghdr
+	ctrlCmdGetFamily   = 3
+	ctrlAttrFamilyId   = 1
+	ctrlAttrFamilyName = 2
+
+	nl80211CmdGetInterface = 5
+	nl80211CmdGetStation   = 17
+
+	nl80211AttrIfindex   = 3
+	nl80211AttrMac       = 6
+	nl80211AttrStaInfo   = 21
+	nl80211AttrWiphyFreq = 38
+	nl80211AttrSsid      = 52
+
+	nl80211StaInfoSignal    = 7
+	nl80211StaInfoTxBitrate = 8
+	nl80211StaInfoRxBitrate = 14
+
+	nl80211RateInfoBitrate   = 1 // u16, in 100 kbit/s
+	nl80211RateInfoBitrate32 = 5 // u32, in 100 kbit/s
+
+	// Attribute types carry the nested and byte order flags in their top
+	// bits
+	nlaTypeMask = 0x3fff
+)
+
+// nl80211Link asks the kernel through nl80211 for the link of the named
+// interface, as `iw dev <name> link` does: the SSID and frequency of the
+// interface, and the BSSID, signal and bitrates of the access point it is
+// connected to.
+func nl80211Link(name string) (WirelessLink, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return WirelessLink{}, err
+	}
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_GENERIC)
+	if err != nil {
+		return WirelessLink{}, fmt.Errorf("nl80211: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	replies, err := genlRequest(fd, 1, genlIdCtrl, ctrlCmdGetFamily, 0, netlinkAttr(ctrlAttrFamilyName, []byte("nl80211\x00")))
+	if errors.Is(err, syscall.ENOENT) {
+		return WirelessLink{}, errors.New("nl80211: not available, is cfg80211 loaded?")
+	}
+	if err != nil {
+		return WirelessLink{}, fmt.Errorf("nl80211: %w", err)
+	}
+	var family uint16
+	for _, attrs := range replies {
+		if id := attrs[ctrlAttrFamilyId]; len(id) >= 2 {
+			family = binary.NativeEndian.Uint16(id)
+		}
+	}
+	if family == 0 {
+		return WirelessLink{}, errors.New("nl80211: no family id")
+	}
+
+	ifindex := make([]byte, 4)
+	binary.NativeEndian.PutUint32(ifindex, uint32(iface.Index))
+	l := WirelessLink{Interface: name}
+
+	replies, err = genlRequest(fd, 2, family, nl80211CmdGetInterface, 0, netlinkAttr(nl80211AttrIfindex, ifindex))
+	if err != nil {
+		return WirelessLink{}, fmt.Errorf("nl80211: %w", err)
+	}
+	for _, attrs := range replies {
+		parseNl80211Interface(&l, attrs)
+	}
+
+	// In station mode the only station is the access point
+	replies, err = genlRequest(fd, 3, family, nl80211CmdGetStation, syscall.NLM_F_DUMP, netlinkAttr(nl80211AttrIfindex, ifindex))
+	if err != nil {
+		return WirelessLink{}, fmt.Errorf("nl80211: %w", err)
+	}
+	for _, attrs := range replies {
+		parseNl80211Station(&l, attrs)
+	}
+	if !l.Connected {
+		// The interface keeps its last SSID and frequency
+		return WirelessLink{Interface: name}, nil
+	}
+	return l, nil
+}
+
+// genlRequest sends a generic netlink command and returns the attributes of
+// every reply. Without NLM_F_DUMP the kernel sends a single reply.
+func genlRequest(fd int, seq uint32, family uint16, cmd uint8, flags uint16, attrs []byte) ([]map[uint16][]byte, error) {
+	req := make([]byte, syscall.NLMSG_HDRLEN+genlHdrLen, syscall.NLMSG_HDRLEN+genlHdrLen+len(attrs))
+	req = append(req, attrs...)
+	binary.NativeEndian.PutUint32(req[0:], uint32(len(req)))
+	binary.NativeEndian.PutUint16(req[4:], family)
+	binary.NativeEndian.PutUint16(req[6:], syscall.NLM_F_REQUEST|flags)
+	binary.NativeEndian.PutUint32(req[8:], seq)
+	req[syscall.NLMSG_HDRLEN] = cmd
+	req[syscall.NLMSG_HDRLEN+1] = 1 // version
+
+	if err := syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	var replies []map[uint16][]byte
+	buf := make([]byte, 1<<16)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			if msg.Header.Seq != seq {
+				continue
+			}
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				return replies, nil
+			case syscall.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := int32(binary.NativeEndian.Uint32(msg.Data)); errno != 0 {
+						return nil, syscall.Errno(-errno)
+					}
+				}
+				return replies, nil
+			}
+			if len(msg.Data) >= genlHdrLen {
+				replies = append(replies, netlinkAttrs(msg.Data[genlHdrLen:]))
+			}
+		}
+		if flags&syscall.NLM_F_DUMP == 0 && len(replies) > 0 {
+			return replies, nil
+		}
+	}
+}
+
+// parseNl80211Interface fills in the SSID and frequency from the reply to
+// NL80211_CMD_GET_INTERFACE.
+func parseNl80211Interface(l *WirelessLink, attrs map[uint16][]byte) {
+	if ssid := attrs[nl80211AttrSsid]; len(ssid) > 0 {
+		l.SSID = string(ssid)
+	}
+	if freq := attrs[nl80211AttrWiphyFreq]; len(freq) >= 4 {
+		l.Frequency = float64(binary.NativeEndian.Uint32(freq))
+		l.Channel = wifiChannel(l.Frequency)
+		l.Band = wifiBand(l.Frequency)
+	}
+}
+
+// parseNl80211Station fills in the access point from a reply to
+// NL80211_CMD_GET_STATION.
+func parseNl80211Station(l *WirelessLink, attrs map[uint16][]byte) {
+	mac := attrs[nl80211AttrMac]
+	if len(mac) != 6 || l.Connected {
+		return
+	}
+	l.Connected = true
+	l.BSSID = net.HardwareAddr(mac).String()
+
+	info := netlinkAttrs(attrs[nl80211AttrStaInfo])
+	if signal := info[nl80211StaInfoSignal]; len(signal) >= 1 {
+		l.Signal = float64(int8(signal[0]))
+	}
+	l.TxBitrate = nl80211Bitrate(info[nl80211StaInfoTxBitrate])
+	l.RxBitrate = nl80211Bitrate(info[nl80211StaInfoRxBitrate])
+}
+
+// nl80211Bitrate returns the Mbit/s of a nested struct rate_info.
+func nl80211Bitrate(data []byte) float64 {
+	rate := netlinkAttrs(data)
+	if b := rate[nl80211RateInfoBitrate32]; len(b) >= 4 {
+		return float64(binary.NativeEndian.Uint32(b)) / 10
+	}
+	if b := rate[nl80211RateInfoBitrate]; len(b) >= 2 {
+		return float64(binary.NativeEndian.Uint16(b)) / 10
+	}
+	return 0
+}
+
+// netlinkAttrs indexes a run of netlink attributes by type.
+func netlinkAttrs(data []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+	for len(data) >= syscall.SizeofRtAttr {
+		size := int(binary.NativeEndian.Uint16(data[0:]))
+		kind := binary.NativeEndian.Uint16(data[2:]) & nlaTypeMask
+		if size < syscall.SizeofRtAttr || size > len(data) {
+			break
+		}
+		attrs[kind] = data[syscall.SizeofRtAttr:size]
+		// Attributes are padded to 4 bytes
+		data = data[min(len(data), (size+3)&^3):]
+	}
+	return attrs
+}
+
+// netlinkAttr encodes one attribute, padded to 4 bytes.
+func netlinkAttr(kind uint16, value []byte) []byte {
+	size := syscall.SizeofRtAttr + len(value)
+	attr := make([]byte, (size+3)&^3)
+	binary.NativeEndian.PutUint16(attr[0:], uint16(size))
+	binary.NativeEndian.PutUint16(attr[2:], kind)
+	copy(attr[syscall.SizeofRtAttr:], value)
+	return attr
+}
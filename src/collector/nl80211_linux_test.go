@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestParseNl80211(t *testing.T) {
+	u32 := func(v uint32) []byte {
+		b := make([]byte, 4)
+		binary.NativeEndian.PutUint32(b, v)
+		return b
+	}
+	concat := func(attrs ...[]byte) []byte {
+		var b []byte
+		for _, a := range attrs {
+			b = append(b, a...)
+		}
+		return b
+	}
+
+	var l WirelessLink
+	parseNl80211Interface(&l, netlinkAttrs(concat(
+		netlinkAttr(nl80211AttrIfindex, u32(3)),
+		netlinkAttr(nl80211AttrSsid, []byte("Home Net")),
+		netlinkAttr(nl80211AttrWiphyFreq, u32(5955)),
+	)))
+	if l.SSID != "Home Net" || l.Frequency != 5955 || l.Channel != 1 || l.Band != "6GHz" {
+		t.Errorf("parseNl80211Interface() = %+v", l)
+	}
+
+	legacy := make([]byte, 2)
+	binary.NativeEndian.PutUint16(legacy, 540)
+	// Nested attributes have the top bit of their type set
+	staInfo := concat(
+		netlinkAttr(nl80211StaInfoSignal, []byte{0xc8}), // -56
+		netlinkAttr(nl80211StaInfoTxBitrate|0x8000, netlinkAttr(nl80211RateInfoBitrate, legacy)),
+		netlinkAttr(nl80211StaInfoRxBitrate|0x8000, concat(
+			netlinkAttr(nl80211RateInfoBitrate, legacy),
+			netlinkAttr(nl80211RateInfoBitrate32, u32(8667)),
+		)),
+	)
+	station := netlinkAttrs(concat(
+		netlinkAttr(nl80211AttrMac, []byte{0x3c, 0x22, 0xfb, 0x8a, 0x11, 0x08}),
+		netlinkAttr(nl80211AttrStaInfo|0x8000, staInfo),
+	))
+	parseNl80211Station(&l, station)
+	if !l.Connected || l.BSSID != "3c:22:fb:8a:11:08" || l.Signal != -56 || l.TxBitrate != 54 || l.RxBitrate != 866.7 {
+		t.Errorf("parseNl80211Station() = %+v", l)
+	}
+
+	// Only the first station, the access point, counts
+	other := netlinkAttrs(netlinkAttr(nl80211AttrMac, []byte{1, 2, 3, 4, 5, 6}))
+	if parseNl80211Station(&l, other); l.BSSID != "3c:22:fb:8a:11:08" {
+		t.Errorf("BSSID = %s after a second station", l.BSSID)
+	}
+}
+
+func TestNetlinkAttrs(t *testing.T) {
+	// A 1 byte attribute padded to 4, then a truncated one
+	data := append(netlinkAttr(7, []byte{42}), 12, 0, 1, 0)
+	attrs := netlinkAttrs(data)
+	if len(attrs) != 1 || len(attrs[7]) != 1 || attrs[7][0] != 42 {
+		t.Errorf("netlinkAttrs() = %v", attrs)
+	}
+}
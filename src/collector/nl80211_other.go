@@ -0,0 +1,10 @@
+//go:build !linux
+
+package collector
+
+import "errors"
+
+// nl80211Link needs the Linux nl80211 netlink interface.
+func nl80211Link(name string) (WirelessLink, error) {
+	return WirelessLink{}, errors.New("nl80211: not supported on this system")
+}
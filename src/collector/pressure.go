@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// PressureResources are the resources the kernel reports stalls for.
+var PressureResources = []string{"cpu", "memory", "io"}
+
+// PressureThreshold raises an alert when a stall average goes above Percent.
+type PressureThreshold struct {
+	Resource string  `json:"resource"` // "cpu", "memory" or "io"
+	Full     bool    `json:"full"`     // use the "full" line instead of "some"
+	Window   int     `json:"window"`   // 10, 60 or 300 seconds
+	Percent  float64 `json:"percent"`
+}
+
+func (t PressureThreshold) String() string {
+	kind := "some"
+	if t.Full {
+		kind = "full"
+	}
+	return fmt.Sprintf("%s %s avg%d > %g%%", t.Resource, kind, t.Window, t.Percent)
+}
+
+// DefaultPressureThresholds flag hosts that are starved rather than merely
+// busy: sustained CPU queueing, and any meaningful time in which every task
+// was stuck on memory or I/O.
+var DefaultPressureThresholds = []PressureThreshold{
+	{Resource: "cpu", Window: 60, Percent: 50},
+	{Resource: "memory", Full: true, Window: 10, Percent: 5},
+	{Resource: "memory", Window: 60, Percent: 20},
+	{Resource: "io", Full: true, Window: 10, Percent: 10},
+	{Resource: "io", Window: 60, Percent: 40},
+}
+
+// PressureAlert is a threshold that was exceeded.
+type PressureAlert struct {
+	Threshold PressureThreshold `json:"threshold"`
+	Cgroup    string            `json:"cgroup,omitempty"` // empty for the whole system
+	Value     float64           `json:"value"`
+}
+
+// CgroupPressure is the pressure inside one cgroup.
+type CgroupPressure struct {
+	Path     string                     `json:"path"`
+	Pressure map[string]procfs.PSIStats `json:"pressure"`
+}
+
+type PressureSample struct {
+	Pressure map[string]procfs.PSIStats `json:"pressure"` // keyed by resource
+	Cgroups  []CgroupPressure           `json:"cgroups,omitempty"`
+	Alerts   []PressureAlert            `json:"alerts,omitempty"`
+}
+
+// PressureCollector reads Pressure Stall Information for the whole system
+// and for the cgroups down to cgroupDepth (cgroup v2 only).
+type PressureCollector struct {
+	cfg        Config
+	thresholds []PressureThreshold
+}
+
+func NewPressureCollector(cfg Config, thresholds []PressureThreshold) *PressureCollector {
+	return &PressureCollector{cfg: cfg, thresholds: thresholds}
+}
+
+func (c *PressureCollector) Name() string { return Pressure }
+
+// Interval matches the rate at which the kernel updates the averages.
+func (c *PressureCollector) Interval() time.Duration { return 2 * time.Second }
+
+func (c *PressureCollector) Collect(ctx context.Context) (Sample, error) {
+	fs := c.cfg.procFS()
+
+	s := PressureSample{Pressure: make(map[string]procfs.PSIStats)}
+	for _, res := range PressureResources {
+		stats, err := fs.Pressure(res)
+		if err != nil {
+			// Missing with CONFIG_PSI=n or psi=0 on the kernel command line
+			return nil, fmt.Errorf("pressure stall information unavailable: %w", err)
+		}
+		s.Pressure[res] = stats
+	}
+	s.Alerts = c.check("", s.Pressure)
+
+	s.Cgroups = c.cgroups()
+	for _, cg := range s.Cgroups {
+		s.Alerts = append(s.Alerts, c.check(cg.Path, cg.Pressure)...)
+	}
+	return s, nil
+}
+
+// cgroupDepth is how far below the root cgroups are read: deep enough for
+// the services and containers in system.slice/docker-<id>.scope.
+const cgroupDepth = 3
+
+// cgroups reads the pressure files of the cgroups below the root, parents
+// before their children.
+func (c *PressureCollector) cgroups() []CgroupPressure {
+	return c.walkCgroups(c.cfg.sysPath("fs/cgroup"), "", 1, nil)
+}
+
+func (c *PressureCollector) walkCgroups(dir, path string, depth int, groups []CgroupPressure) []CgroupPressure {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return groups
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		cg := CgroupPressure{Path: path + "/" + e.Name(), Pressure: make(map[string]procfs.PSIStats)}
+		for _, res := range PressureResources {
+			if stats, err := procfs.ReadPSI(filepath.Join(dir, e.Name(), res+".pressure")); err == nil {
+				cg.Pressure[res] = stats
+			}
+		}
+		// Without PSI in a cgroup there is none in its children either
+		if len(cg.Pressure) == 0 {
+			continue
+		}
+		groups = append(groups, cg)
+		if depth < cgroupDepth {
+			groups = c.walkCgroups(filepath.Join(dir, e.Name()), cg.Path, depth+1, groups)
+		}
+	}
+	return groups
+}
+
+func (c *PressureCollector) check(cgroup string, pressure map[string]procfs.PSIStats) []PressureAlert {
+	var alerts []PressureAlert
+	for _, t := range c.thresholds {
+		stats, ok := pressure[t.Resource]
+		if !ok {
+			continue
+		}
+		line := stats.Some
+		if t.Full {
+			if stats.Full == nil {
+				continue
+			}
+			line = *stats.Full
+		}
+		if v, ok := line.Window(t.Window); ok && v > t.Percent {
+			alerts = append(alerts, PressureAlert{Threshold: t, Cgroup: cgroup, Value: v})
+		}
+	}
+	return alerts
+}
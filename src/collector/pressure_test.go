@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPressureCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewPressureCollector(cfg, DefaultPressureThresholds)
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(PressureSample)
+
+	if cpu := s.Pressure["cpu"]; cpu.Some.Avg10 != 12.5 || cpu.Some.Avg300 != 3.25 || cpu.Some.Total != 123456789 {
+		t.Errorf("cpu pressure = %+v", cpu)
+	}
+	if io := s.Pressure["io"]; io.Full == nil || io.Full.Avg60 != 15 {
+		t.Errorf("io pressure = %+v", io)
+	}
+
+	var paths []string
+	for _, cg := range s.Cgroups {
+		paths = append(paths, cg.Path)
+	}
+	if want := []string{"/system.slice", "/system.slice/docker-4f2a.scope", "/user.slice"}; fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Fatalf("Cgroups = %q, want %q", paths, want)
+	}
+	if got := s.Cgroups[2].Pressure["cpu"].Some.Avg10; got != 11 {
+		t.Errorf("user.slice cpu some avg10 = %v, want 11", got)
+	}
+
+	var alerts []string
+	for _, a := range s.Alerts {
+		alerts = append(alerts, fmt.Sprintf("%s:%s=%g", a.Cgroup, a.Threshold, a.Value))
+	}
+	want := []string{
+		":memory full avg10 > 5%=7.5",
+		":io full avg10 > 10%=20",
+		"/system.slice:io full avg10 > 10%=18",
+		"/system.slice/docker-4f2a.scope:cpu some avg60 > 50%=62",
+		"/system.slice/docker-4f2a.scope:io full avg10 > 10%=16",
+		"/user.slice:memory full avg10 > 5%=7",
+	}
+	if fmt.Sprint(alerts) != fmt.Sprint(want) {
+		t.Errorf("Alerts = %q, want %q", alerts, want)
+	}
+}
+
+func TestPressureCgroupDepth(t *testing.T) {
+	cfg := fixtureConfig(t)
+	// PSI stops below "none", and "d" is deeper than cgroupDepth
+	for _, dir := range []string{"a", "a/b", "a/b/c", "a/b/c/d", "a/none/e"} {
+		path := cfg.sysPath("fs/cgroup", dir)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(dir, "none") {
+			psi := "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"
+			if err := os.WriteFile(filepath.Join(path, "cpu.pressure"), []byte(psi), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var paths []string
+	for _, cg := range NewPressureCollector(cfg, nil).cgroups() {
+		paths = append(paths, cg.Path)
+	}
+	want := []string{"/a", "/a/b", "/a/b/c", "/system.slice", "/system.slice/docker-4f2a.scope", "/user.slice"}
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("cgroups = %q, want %q", paths, want)
+	}
+}
+
+func TestPressureUnavailable(t *testing.T) {
+	cfg := fixtureConfig(t)
+	if err := os.RemoveAll(cfg.procPath("pressure")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPressureCollector(cfg, nil).Collect(context.Background()); err == nil {
+		t.Errorf("Collect succeeded without /proc/pressure")
+	}
+}
+
+func TestPressureWithoutCpuFull(t *testing.T) {
+	cfg := fixtureConfig(t)
+	// Kernels before 5.13 have no "full" line for the system-wide CPU
+	writeFixture(t, cfg, "pressure/cpu", "some avg10=90.00 avg60=90.00 avg300=90.00 total=1\n")
+
+	c := NewPressureCollector(cfg, []PressureThreshold{{Resource: "cpu", Full: true, Window: 10, Percent: 1}})
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(PressureSample)
+	if s.Pressure["cpu"].Full != nil {
+		t.Errorf("Full = %+v, want nil", s.Pressure["cpu"].Full)
+	}
+	for _, a := range s.Alerts {
+		if a.Cgroup == "" {
+			t.Errorf("alert raised on a missing full line: %+v", a)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"context"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+type ProcessStat struct {
+	Pid        int     `json:"pid"`
+	PPid       int     `json:"ppid"`
+	Name       string  `json:"name"`
+	Cmdline    string  `json:"cmdline"`
+	User       string  `json:"user"`
+	State      string  `json:"state"`
+	Threads    int     `json:"threads"`
+	Nice       int     `json:"nice"`
+	CpuPercent float64 `json:"cpu_percent"`
+	MemPercent float64 `json:"mem_percent"`
+	RSS        uint64  `json:"rss_bytes"`
+	PSS        uint64  `json:"pss_bytes"` // only read by Detail, smaps_rollup is slow
+
+}
+
+type ProcessSample struct {
+	Processes []ProcessStat `json:"processes"`
+}
+
+// ProcessCollector reads every process from procfs. CPU usage is computed
+// from the jiffies consumed since the previous sample, like top does, so
+// 100% means one fully busy core.
+type ProcessCollector struct {
+	fs procfs.FS
+
+	mu        sync.Mutex
+	lastTotal uint64
+	lastTime  time.Time
+	lastTimes map[int]uint64
+	lastStats map[int]ProcessStat
+	users     map[int]string
+}
+
+func NewProcessCollector(cfg Config) *ProcessCollector {
+	return &ProcessCollector{
+		fs:        cfg.procFS(),
+		lastTimes: make(map[int]uint64),
+		users:     make(map[int]string),
+	}
+}
+
+func (c *ProcessCollector) Name() string { return Processes }
+
+func (c *ProcessCollector) Interval() time.Duration { return 2 * time.Second }
+
+func (c *ProcessCollector) Collect(ctx context.Context) (Sample, error) {
+	// Before listing /proc, so processes started during the walk count
+	// as started after this sample
+	now := time.Now()
+	stat, err := c.fs.Stat()
+	if err != nil {
+		return nil, err
+	}
+	meminfo, err := c.fs.Meminfo()
+	if err != nil {
+		return nil, err
+	}
+	procs, err := c.fs.AllProcs()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := stat.CpuTotal.Total()
+	var elapsed float64
+	if c.lastTotal > 0 && total > c.lastTotal && len(stat.Cpu) > 0 {
+		// Ticks of a single core elapsed since the last sample
+		elapsed = float64(total-c.lastTotal) / float64(len(stat.Cpu))
+	}
+
+	s := ProcessSample{
+		Processes: make([]ProcessStat, 0, len(procs)),
+	}
+	times := make(map[int]uint64, len(procs))
+	stats := make(map[int]ProcessStat, len(procs))
+	for _, p := range procs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		// Processes can exit while we walk /proc; skip them.
+		ps, err := p.Stat()
+		if err != nil {
+			continue
+		}
+		status, err := p.Status()
+		if err != nil {
+			continue
+		}
+
+		ticks := ps.CPUTime()
+		times[p.PID] = ticks
+
+		item := ProcessStat{
+			Pid:     p.PID,
+			PPid:    ps.PPID,
+			Name:    ps.Comm,
+			User:    c.lookupUser(status.UID),
+			State:   ps.State,
+			Threads: int(ps.NumThreads),
+			Nice:    int(ps.Nice),
+			RSS:     status.VmRSS,
+		}
+		if args, err := p.Cmdline(); err == nil {
+			item.Cmdline = strings.Join(args, " ")
+		}
+		if elapsed > 0 {
+			last, ok := c.lastTimes[p.PID]
+			switch {
+			case ok && last <= ticks:
+				ticks -= last
+			case processStart(stat.BootTime, ps).After(c.lastTime):
+				// Started after the last sample, so all of its ticks
+				// belong to this interval
+			default:
+				// Running but missed by the last sample; its ticks
+				// span its whole life, not this interval
+				ticks = 0
+			}
+			item.CpuPercent = float64(ticks) / elapsed * 100
+		}
+		if meminfo.MemTotal > 0 {
+			item.MemPercent = float64(item.RSS) / float64(meminfo.MemTotal) * 100
+		}
+		s.Processes = append(s.Processes, item)
+		stats[p.PID] = item
+	}
+
+	c.lastTotal = total
+	c.lastTime = now
+	c.lastTimes = times
+	c.lastStats = stats
+	return s, nil
+}
+
+// processStart is when a process started, from the boot time in seconds
+// and its start time in ticks after boot.
+func processStart(bootTime uint64, stat procfs.ProcStat) time.Time {
+	started := bootTime*procfs.UserHZ + stat.StartTime
+	return time.Unix(int64(started/procfs.UserHZ), int64(started%procfs.UserHZ)*int64(time.Second/procfs.UserHZ))
+}
+
+// lookupUser resolves a uid to a user name, falling back to the number.
+func (c *ProcessCollector) lookupUser(uid int) string {
+	if name, ok := c.users[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	c.users[uid] = name
+	return name
+}
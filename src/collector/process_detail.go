@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+// RedactEnvPattern matches the names of environment variables whose values
+// are hidden in process details.
+var RedactEnvPattern = regexp.MustCompile(`(?i)(pass|secret|token|key|auth|cred|cookie|session|private)`)
+
+// ProcessDetail is everything we know about a single process. Fields that
+// could not be read, usually for lack of permission, are listed in
+// Unreadable and left empty.
+type ProcessDetail struct {
+	ProcessStat
+
+	Cwd       string             `json:"cwd"`
+	Environ   []string           `json:"environ"`
+	FDCount   int                `json:"fd_count"`
+	Cgroups   []string           `json:"cgroups"`
+	StartTime time.Time          `json:"start_time"`
+	IO        procfs.ProcIO      `json:"io"`
+	Maps      procfs.MapsSummary `json:"maps"`
+
+	Unreadable []string `json:"unreadable,omitempty"`
+}
+
+// ProcessDetailer looks up the details of a single process on demand.
+type ProcessDetailer interface {
+	Detail(ctx context.Context, pid int) (ProcessDetail, error)
+}
+
+// Detail reads the details of pid. Usage figures come from the most recent
+// sample, so Collect should have run at least once.
+func (c *ProcessCollector) Detail(ctx context.Context, pid int) (ProcessDetail, error) {
+	p := c.fs.Proc(pid)
+	stat, err := p.Stat()
+	if err != nil {
+		return ProcessDetail{}, err
+	}
+
+	c.mu.Lock()
+	d := ProcessDetail{ProcessStat: c.lastStats[pid]}
+	c.mu.Unlock()
+	if d.Pid != pid {
+		// Not in the last sample yet; fill in what the stat file tells us
+		d.ProcessStat = ProcessStat{
+			Pid:     pid,
+			PPid:    stat.PPID,
+			Name:    stat.Comm,
+			State:   stat.State,
+			Threads: int(stat.NumThreads),
+			Nice:    int(stat.Nice),
+		}
+	}
+
+	if sys, err := c.fs.Stat(); err == nil {
+		d.StartTime = processStart(sys.BootTime, stat)
+	}
+
+	if d.Cwd, err = p.Cwd(); err != nil {
+		d.Unreadable = append(d.Unreadable, "cwd")
+	}
+	if env, err := p.Environ(); err != nil {
+		d.Unreadable = append(d.Unreadable, "environ")
+	} else {
+		d.Environ = redactEnviron(env)
+	}
+	if d.FDCount, err = p.FDCount(); err != nil {
+		d.Unreadable = append(d.Unreadable, "fd")
+	}
+	if d.Cgroups, err = p.Cgroups(); err != nil {
+		d.Unreadable = append(d.Unreadable, "cgroup")
+	}
+	if d.IO, err = p.IO(); err != nil {
+		d.Unreadable = append(d.Unreadable, "io")
+	}
+	if d.Maps, err = p.MapsSummary(); err != nil {
+		d.Unreadable = append(d.Unreadable, "maps")
+	}
+	if rollup, err := p.SmapsRollup(); err != nil {
+		d.Unreadable = append(d.Unreadable, "smaps")
+	} else {
+		d.PSS = rollup.Pss
+	}
+
+	return d, nil
+}
+
+func redactEnviron(env []string) []string {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if RedactEnvPattern.MatchString(key) {
+			kv = key + "=********"
+		}
+		out[i] = kv
+	}
+	return out
+}
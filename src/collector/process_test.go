@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test/src/procfs"
+)
+
+func TestProcessCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewProcessCollector(cfg)
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 200 ticks elapse on each of the two cores; pid 100 uses 100 of them.
+	writeFixture(t, cfg, "stat", "cpu  1200 0 600 8100 100 0 0 0 0 0\ncpu0 600 0 300 4050 50 0 0 0 0 0\ncpu1 600 0 300 4050 50 0 0 0 0 0\n")
+	writeFixture(t, cfg, "100/stat", "100 (Web Content) R 1 100 100 0 -1 4194304 500 0 0 0 480 120 0 0 20 5 4 0 5000 900000000 20000 18446744073709551615\n")
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	procs := make(map[int]ProcessStat)
+	for _, p := range sample.(ProcessSample).Processes {
+		procs[p.Pid] = p
+	}
+
+	tests := []struct {
+		pid     int
+		name    string
+		cmdline string
+		state   string
+		ppid    int
+		threads int
+		cpu     float64
+		rss     uint64
+	}{
+		{1, "systemd", "/sbin/init splash", "S", 0, 1, 0, 12000 * 1024},
+		{100, "Web Content", "/usr/lib/firefox/firefox -contentproc", "R", 1, 4, 50, 80000 * 1024},
+		{200, "kworker/0:1", "", "I", 2, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		p, ok := procs[tt.pid]
+		if !ok {
+			t.Errorf("pid %d missing", tt.pid)
+			continue
+		}
+		if p.Name != tt.name || p.Cmdline != tt.cmdline || p.State != tt.state || p.PPid != tt.ppid || p.Threads != tt.threads {
+			t.Errorf("pid %d: unexpected process %+v", tt.pid, p)
+		}
+		if math.Abs(p.CpuPercent-tt.cpu) > 0.001 {
+			t.Errorf("pid %d: CpuPercent = %v, want %v", tt.pid, p.CpuPercent, tt.cpu)
+		}
+		// PSS is left to Detail
+		if p.RSS != tt.rss || p.PSS != 0 {
+			t.Errorf("pid %d: RSS %d PSS %d, want %d 0", tt.pid, p.RSS, p.PSS, tt.rss)
+		}
+	}
+	if got := procs[1].User; got != "root" {
+		t.Errorf("pid 1: User = %q, want %q", got, "root")
+	}
+}
+
+func TestProcessCollectorNewPids(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewProcessCollector(cfg)
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 200 ticks elapse on each of the two cores. Both new pids used 40
+	// ticks in total, but only 501 started after the first sample.
+	const btime = 1700000000
+	writeFixture(t, cfg, "stat", fmt.Sprintf("cpu  1200 0 600 8100 100 0 0 0 0 0\ncpu0 600 0 300 4050 50 0 0 0 0 0\ncpu1 600 0 300 4050 50 0 0 0 0 0\nbtime %d\n", btime))
+	started := map[int]int64{
+		500: 5000,
+		501: (time.Now().Unix() - btime + 1) * procfs.UserHZ,
+	}
+	for pid, start := range started {
+		if err := os.Mkdir(cfg.procPath(strconv.Itoa(pid)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFixture(t, cfg, fmt.Sprintf("%d/stat", pid), fmt.Sprintf("%d (worker) R 1 %d %d 0 -1 4194304 0 0 0 0 30 10 0 0 20 0 1 0 %d 0 0 18446744073709551615\n", pid, pid, pid, start))
+		writeFixture(t, cfg, fmt.Sprintf("%d/status", pid), fmt.Sprintf("Name:\tworker\nPid:\t%d\nUid:\t0\t0\t0\t0\n", pid))
+	}
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]float64{500: 0, 501: 20}
+	for _, p := range sample.(ProcessSample).Processes {
+		if cpu, ok := want[p.Pid]; ok {
+			if math.Abs(p.CpuPercent-cpu) > 0.001 {
+				t.Errorf("pid %d: CpuPercent = %v, want %v", p.Pid, p.CpuPercent, cpu)
+			}
+			delete(want, p.Pid)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("pids %v missing", want)
+	}
+}
+
+func TestProcessDetail(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewProcessCollector(cfg)
+	if _, err := c.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := c.Detail(context.Background(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Web Content" || d.User == "" || d.RSS != 80000*1024 {
+		t.Errorf("Detail did not carry over the sampled stats: %+v", d.ProcessStat)
+	}
+	if d.PSS != 60000*1024 {
+		t.Errorf("PSS = %d, want %d", d.PSS, 60000*1024)
+	}
+	if d.Cwd != "/home/user/projects" {
+		t.Errorf("Cwd = %q", d.Cwd)
+	}
+	if d.FDCount != 3 {
+		t.Errorf("FDCount = %d, want 3", d.FDCount)
+	}
+	if want := time.Unix(1700000050, 0); !d.StartTime.Equal(want) {
+		t.Errorf("StartTime = %v, want %v", d.StartTime, want)
+	}
+	if len(d.Cgroups) != 1 || d.Cgroups[0] != "0::/user.slice/user-1000.slice/session-2.scope" {
+		t.Errorf("Cgroups = %q", d.Cgroups)
+	}
+	if d.IO.ReadBytes != 4096000 || d.IO.WriteBytes != 819200 || d.IO.RChar != 5242880 {
+		t.Errorf("IO = %+v", d.IO)
+	}
+	wantMaps := procfs.MapsSummary{Count: 5, Total: 0x100000 + 0x400000 + 0x800000 + 0x21000 + 0x2000, File: 0x100000, Anon: 0x800000, Heap: 0x400000, Stack: 0x21000, Other: 0x2000}
+	if d.Maps != wantMaps {
+		t.Errorf("Maps = %+v, want %+v", d.Maps, wantMaps)
+	}
+
+	wantEnv := []string{"HOME=/home/user", "LANG=en_US.UTF-8", "GITHUB_TOKEN=********", "DB_PASSWORD=********"}
+	if strings.Join(d.Environ, "\n") != strings.Join(wantEnv, "\n") {
+		t.Errorf("Environ = %q, want %q", d.Environ, wantEnv)
+	}
+	if len(d.Unreadable) != 0 {
+		t.Errorf("Unreadable = %q, want none", d.Unreadable)
+	}
+
+	// pid 1 has none of the optional files, as if they were unreadable
+	d, err = c.Detail(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Unreadable) != 7 {
+		t.Errorf("Unreadable = %q, want every optional field", d.Unreadable)
+	}
+
+	if _, err := c.Detail(context.Background(), 999); err == nil {
+		t.Errorf("Detail of a missing pid did not fail")
+	}
+}
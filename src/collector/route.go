@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Route flags from <linux/route.h>.
+const (
+	rtfUp     = 0x0001
+	rtfReject = 0x0200
+)
+
+// Route is a default route.
+type Route struct {
+	Interface string `json:"interface"`
+	Gateway   string `json:"gateway,omitempty"` // empty for on-link routes
+	Metric    uint64 `json:"metric"`
+}
+
+// defaultRoutes holds the preferred default route of each family, with an
+// empty Interface when the family has none.
+type defaultRoutes struct {
+	IPv4 Route
+	IPv6 Route
+}
+
+// defaultRoutes reads the kernel routing tables for the default routes with
+// the lowest metric.
+func (c Config) defaultRoutes() defaultRoutes {
+	return defaultRoutes{
+		IPv4: readDefaultRoute(c.procPath("net/route"), parseIPv4Route),
+		IPv6: readDefaultRoute(c.procPath("net/ipv6_route"), parseIPv6Route),
+	}
+}
+
+// readDefaultRoute returns the default route with the lowest metric among
+// the lines of path that parse accepts.
+func readDefaultRoute(path string, parse func(fields []string) (Route, bool)) Route {
+	f, err := os.Open(path)
+	if err != nil {
+		return Route{}
+	}
+	defer f.Close()
+
+	var best Route
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		r, ok := parse(strings.Fields(scanner.Text()))
+		if ok && (best.Interface == "" || r.Metric < best.Metric) {
+			best = r
+		}
+	}
+	return best
+}
+
+// parseIPv4Route parses a default route from /proc/net/route:
+//
+//	Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+//
+// Addresses are hexadecimal in host byte order.
+func parseIPv4Route(fields []string) (Route, bool) {
+	if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+		return Route{}, false
+	}
+	flags, err := strconv.ParseUint(fields[3], 16, 32)
+	if err != nil || flags&rtfUp == 0 || flags&rtfReject != 0 {
+		return Route{}, false
+	}
+	metric, err := strconv.ParseUint(fields[6], 10, 32)
+	if err != nil {
+		return Route{}, false
+	}
+	r := Route{Interface: fields[0], Metric: metric}
+	if gw, err := strconv.ParseUint(fields[2], 16, 32); err == nil && gw != 0 {
+		ip := make(net.IP, net.IPv4len)
+		binary.NativeEndian.PutUint32(ip, uint32(gw))
+		r.Gateway = ip.String()
+	}
+	return r, true
+}
+
+// parseIPv6Route parses a default route from /proc/net/ipv6_route:
+//
+//	Destination PrefixLen Source PrefixLen NextHop Metric RefCnt Use Flags Iface
+//
+// Addresses are 32 hexadecimal digits in network byte order, and the
+// numbers hexadecimal too.
+func parseIPv6Route(fields []string) (Route, bool) {
+	if len(fields) < 10 || strings.Trim(fields[0], "0") != "" || fields[1] != "00" {
+		return Route{}, false
+	}
+	flags, err := strconv.ParseUint(fields[8], 16, 32)
+	if err != nil || flags&rtfUp == 0 || flags&rtfReject != 0 {
+		return Route{}, false
+	}
+	metric, err := strconv.ParseUint(fields[5], 16, 32)
+	if err != nil {
+		return Route{}, false
+	}
+	r := Route{Interface: fields[9], Metric: metric}
+	if gw, err := hex.DecodeString(fields[4]); err == nil && len(gw) == net.IPv6len && !net.IP(gw).IsUnspecified() {
+		r.Gateway = net.IP(gw).String()
+	}
+	return r, true
+}
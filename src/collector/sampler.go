@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Update is the outcome of one collection run.
+type Update struct {
+	Name   string
+	Sample Sample
+	Err    error
+	Time   time.Time
+}
+
+// Sampler runs every registered collector in the background on its own
+// interval and keeps the latest result of each. Consumers either read the
+// snapshot directly or subscribe to be notified of every new update.
+type Sampler struct {
+	registry *Registry
+
+	mu     sync.RWMutex
+	latest map[string]Update
+
+	subMu sync.Mutex
+	subs  map[chan Update]struct{}
+}
+
+func NewSampler(registry *Registry) *Sampler {
+	return &Sampler{
+		registry: registry,
+		latest:   make(map[string]Update),
+		subs:     make(map[chan Update]struct{}),
+	}
+}
+
+// Registry returns the collectors being sampled, for callers that need to
+// query one directly.
+func (s *Sampler) Registry() *Registry {
+	return s.registry
+}
+
+// Run starts sampling and blocks until ctx is cancelled.
+func (s *Sampler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, c := range s.registry.All() {
+		wg.Add(1)
+		go func(c Collector) {
+			defer wg.Done()
+			s.loop(ctx, c)
+		}(c)
+	}
+	wg.Wait()
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		close(ch)
+		delete(s.subs, ch)
+	}
+}
+
+func (s *Sampler) loop(ctx context.Context, c Collector) {
+	ticker := time.NewTicker(c.Interval())
+	defer ticker.Stop()
+
+	for {
+		s.collect(ctx, c)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sampler) collect(ctx context.Context, c Collector) {
+	sample, err := c.Collect(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	u := Update{
+		Name:   c.Name(),
+		Sample: sample,
+		Err:    err,
+		Time:   time.Now(),
+	}
+
+	s.mu.Lock()
+	s.latest[u.Name] = u
+	s.mu.Unlock()
+
+	s.publish(u)
+}
+
+func (s *Sampler) publish(u Update) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for ch := range s.subs {
+		// Never block the sampler on a slow subscriber; it can always
+		// catch up through Latest.
+		select {
+		case ch <- u:
+		default:
+		}
+	}
+}
+
+// Latest returns the most recent update of the named collector.
+func (s *Sampler) Latest(name string) (Update, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.latest[name]
+	return u, ok
+}
+
+// Snapshot returns the most recent update of every collector that has run.
+func (s *Sampler) Snapshot() map[string]Update {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := make(map[string]Update, len(s.latest))
+	for name, u := range s.latest {
+		snap[name] = u
+	}
+	return snap
+}
+
+// Subscribe returns a channel that receives every new update, and a function
+// that cancels the subscription. The channel is closed when the sampler stops
+// or the subscription is cancelled.
+func (s *Sampler) Subscribe() (<-chan Update, func()) {
+	ch := make(chan Update, 16)
+
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	cancel := func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		if _, ok := s.subs[ch]; ok {
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSamplerPublishesUpdates(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(stubCollector{name: "stub"})
+	s := NewSampler(r)
+
+	updates, cancelSub := s.Subscribe()
+	defer cancelSub()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case u := <-updates:
+		if u.Name != "stub" || u.Sample != "stub" || u.Err != nil {
+			t.Errorf("unexpected update: %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no update received from sampler")
+	}
+
+	if _, ok := s.Latest("stub"); !ok {
+		t.Errorf("Latest(%q) has no sample after an update was published", "stub")
+	}
+	if snap := s.Snapshot(); len(snap) != 1 {
+		t.Errorf("Snapshot() returned %d entries, want 1", len(snap))
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
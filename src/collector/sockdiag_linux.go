@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+)
+
+// Constants of <linux/sock_diag.h> and <linux/inet_diag.h>.
+const (
+	sockDiagByFamily = 20
+	inetDiagInfo     = 2 // attribute holding struct tcp_info
+
+	inetDiagReqSize = 56 // struct inet_diag_req_v2
+	inetDiagMsgSize = 72 // struct inet_diag_msg
+
+	// Offsets into struct tcp_info, present since Linux 4.2
+	tcpInfoBytesAcked    = 120
+	tcpInfoBytesReceived = 128
+)
+
+// tcpSocketBytes asks the kernel through sock_diag for the byte counters of
+// every TCP socket of our network namespace, keyed by socket inode.
+func tcpSocketBytes() (map[uint64]socketBytes, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_INET_DIAG)
+	if err != nil {
+		return nil, fmt.Errorf("sock_diag: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	sockets := make(map[uint64]socketBytes)
+	for seq, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		if err := sockDiagDump(fd, uint32(seq+1), family, sockets); err != nil {
+			return nil, fmt.Errorf("sock_diag: %w", err)
+		}
+	}
+	return sockets, nil
+}
+
+// sockDiagDump requests the TCP sockets of one address family and adds
+// their counters to sockets.
+func sockDiagDump(fd int, seq uint32, family uint8, sockets map[uint64]socketBytes) error {
+	req := make([]byte, syscall.NLMSG_HDRLEN+inetDiagReqSize)
+	binary.NativeEndian.PutUint32(req[0:], uint32(len(req)))
+	binary.NativeEndian.PutUint16(req[4:], sockDiagByFamily)
+	binary.NativeEndian.PutUint16(req[6:], syscall.NLM_F_REQUEST|syscall.NLM_F_DUMP)
+	binary.NativeEndian.PutUint32(req[8:], seq)
+	body := req[syscall.NLMSG_HDRLEN:]
+	body[0] = family
+	body[1] = syscall.IPPROTO_TCP
+	body[2] = 1 << (inetDiagInfo - 1)                   // extensions
+	binary.NativeEndian.PutUint32(body[4:], ^uint32(0)) // every state
+
+	if err := syscall.Sendto(fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1<<16)
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return err
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if msg.Header.Seq != seq {
+				continue
+			}
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				return nil
+			case syscall.NLMSG_ERROR:
+				if len(msg.Data) >= 4 {
+					if errno := int32(binary.NativeEndian.Uint32(msg.Data)); errno != 0 {
+						return syscall.Errno(-errno)
+					}
+				}
+				return nil
+			}
+			if inode, b, ok := parseInetDiagMsg(msg.Data); ok {
+				sockets[inode] = b
+			}
+		}
+	}
+}
+
+// parseInetDiagMsg returns the inode and counters of a struct inet_diag_msg
+// followed by its attributes.
+func parseInetDiagMsg(data []byte) (uint64, socketBytes, bool) {
+	if len(data) < inetDiagMsgSize {
+		return 0, socketBytes{}, false
+	}
+	inode := uint64(binary.NativeEndian.Uint32(data[68:]))
+
+	attrs := data[inetDiagMsgSize:]
+	for len(attrs) >= syscall.SizeofRtAttr {
+		size := int(binary.NativeEndian.Uint16(attrs[0:]))
+		kind := binary.NativeEndian.Uint16(attrs[2:])
+		if size < syscall.SizeofRtAttr || size > len(attrs) {
+			break
+		}
+		value := attrs[syscall.SizeofRtAttr:size]
+		if kind == inetDiagInfo && len(value) >= tcpInfoBytesReceived+8 {
+			return inode, socketBytes{
+				Sent: binary.NativeEndian.Uint64(value[tcpInfoBytesAcked:]),
+				Recv: binary.NativeEndian.Uint64(value[tcpInfoBytesReceived:]),
+			}, true
+		}
+		// Attributes are padded to 4 bytes
+		attrs = attrs[min(len(attrs), (size+3)&^3):]
+	}
+	// Sockets without tcp_info, e.g. in TIME_WAIT, carry no traffic
+	return inode, socketBytes{}, inode != 0
+}
@@ -0,0 +1,34 @@
+package collector
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestParseInetDiagMsg(t *testing.T) {
+	info := make([]byte, 232)
+	binary.NativeEndian.PutUint64(info[tcpInfoBytesAcked:], 1500)
+	binary.NativeEndian.PutUint64(info[tcpInfoBytesReceived:], 4200)
+
+	msg := make([]byte, inetDiagMsgSize)
+	binary.NativeEndian.PutUint32(msg[68:], 4242)
+	// An unrelated attribute with padding, then tcp_info
+	msg = append(msg, 5, 0, 1, 0, 7, 0, 0, 0)
+	attr := make([]byte, 4)
+	binary.NativeEndian.PutUint16(attr[0:], uint16(4+len(info)))
+	binary.NativeEndian.PutUint16(attr[2:], inetDiagInfo)
+	msg = append(append(msg, attr...), info...)
+
+	inode, b, ok := parseInetDiagMsg(msg)
+	if !ok || inode != 4242 || b != (socketBytes{Recv: 4200, Sent: 1500}) {
+		t.Errorf("parseInetDiagMsg() = %d, %+v, %v", inode, b, ok)
+	}
+
+	// Sockets in TIME_WAIT have no tcp_info
+	if inode, b, ok := parseInetDiagMsg(msg[:inetDiagMsgSize]); !ok || inode != 4242 || b != (socketBytes{}) {
+		t.Errorf("parseInetDiagMsg(no attributes) = %d, %+v, %v", inode, b, ok)
+	}
+	if _, _, ok := parseInetDiagMsg(msg[:20]); ok {
+		t.Errorf("parseInetDiagMsg accepted a truncated message")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package collector
+
+import "errors"
+
+// tcpSocketBytes needs the Linux sock_diag netlink interface.
+func tcpSocketBytes() (map[uint64]socketBytes, error) {
+	return nil, errors.New("sock_diag: not supported on this system")
+}
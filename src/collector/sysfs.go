@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readSysString returns the trimmed contents of a sysfs attribute, or "" if
+// it cannot be read.
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readSysUint returns a numeric sysfs attribute, or 0 if it cannot be read.
+func readSysUint(path string) uint64 {
+	return parseSysUint(readSysString(path))
+}
+
+func parseSysUint(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
+
+// currentChoice returns the bracketed entry of a sysfs choice list, e.g.
+// "zstd" from "lzo [zstd] lz4".
+func currentChoice(s string) string {
+	for _, f := range strings.Fields(s) {
+		if strings.HasPrefix(f, "[") && strings.HasSuffix(f, "]") {
+			return strings.Trim(f, "[]")
+		}
+	}
+	return s
+}
@@ -0,0 +1,183 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wirelessMaxQuality is the link quality cfg80211 reports for a perfect
+// signal in /proc/net/wireless.
+const wirelessMaxQuality = 70
+
+// WirelessLink is the state of a WiFi interface. Everything but Interface
+// and Connected is zero when not connected.
+type WirelessLink struct {
+	Interface string `json:"interface"`
+	Connected bool   `json:"connected"`
+
+	SSID      string  `json:"ssid"`
+	BSSID     string  `json:"bssid"`
+	Frequency float64 `json:"frequency"` // MHz
+	Channel   int     `json:"channel"`
+	Band      string  `json:"band"` // "2.4GHz", "5GHz", "6GHz", "60GHz" or ""
+
+	Signal  float64 `json:"signal"`  // dBm
+	Quality float64 `json:"quality"` // percent
+
+	RxBitrate float64 `json:"rx_bitrate"` // Mbit/s
+	TxBitrate float64 `json:"tx_bitrate"` // Mbit/s
+
+	// Err explains why only the signal and quality are known, when nl80211
+	// could not be queried.
+	Err string `json:"err,omitempty"`
+}
+
+type WirelessSample struct {
+	Links []WirelessLink `json:"links"`
+}
+
+// Link returns the named interface.
+func (s WirelessSample) Link(name string) (WirelessLink, bool) {
+	for _, l := range s.Links {
+		if l.Interface == name {
+			return l, true
+		}
+	}
+	return WirelessLink{}, false
+}
+
+// wirelessLinkFunc describes the link of the named interface from nl80211.
+type wirelessLinkFunc func(name string) (WirelessLink, error)
+
+// WirelessCollector reports the link of every WiFi interface. Signal and
+// quality come from /proc/net/wireless, and the rest from nl80211.
+type WirelessCollector struct {
+	cfg  Config
+	link wirelessLinkFunc
+}
+
+func NewWirelessCollector(cfg Config) *WirelessCollector {
+	return &WirelessCollector{cfg: cfg, link: nl80211Link}
+}
+
+func (c *WirelessCollector) Name() string { return Wireless }
+
+func (c *WirelessCollector) Interval() time.Duration { return 2 * time.Second }
+
+func (c *WirelessCollector) Collect(ctx context.Context) (Sample, error) {
+	entries, err := os.ReadDir(c.cfg.sysPath("class/net"))
+	if err != nil {
+		return nil, err
+	}
+	quality := c.cfg.wirelessQuality()
+
+	var s WirelessSample
+	for _, e := range entries {
+		if isWireless(c.cfg.sysPath("class/net", e.Name())) {
+			s.Links = append(s.Links, wirelessLink(e.Name(), quality, c.link))
+		}
+	}
+	sort.Slice(s.Links, func(i, j int) bool { return s.Links[i].Interface < s.Links[j].Interface })
+	return s, nil
+}
+
+// wirelessStat is a line of /proc/net/wireless.
+type wirelessStat struct {
+	Quality float64 // out of wirelessMaxQuality
+	Signal  float64 // dBm
+}
+
+// wirelessQuality reads /proc/net/wireless, which lists the connected WiFi
+// interfaces:
+//
+//	Inter-| sta-|   Quality        |   Discarded packets               | Missed | WE
+//	 face | tus | link level noise |  nwid  crypt   frag  retry   misc | beacon | 22
+//	 wlan0: 0000   54.  -56.  -256        0      0      0      0     36        0
+func (c Config) wirelessQuality() map[string]wirelessStat {
+	stats := make(map[string]wirelessStat)
+	f, err := os.Open(c.procPath("net/wireless"))
+	if err != nil {
+		return stats
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(rest)
+		if !ok || len(fields) < 3 {
+			continue
+		}
+		// Values updated since the last read end in a dot
+		quality, err1 := strconv.ParseFloat(strings.TrimSuffix(fields[1], "."), 64)
+		signal, err2 := strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stats[strings.TrimSpace(name)] = wirelessStat{Quality: quality, Signal: signal}
+	}
+	return stats
+}
+
+// wirelessLink describes the link of the named interface, given the
+// contents of /proc/net/wireless.
+func wirelessLink(name string, quality map[string]wirelessStat, link wirelessLinkFunc) WirelessLink {
+	l, err := link(name)
+	if err != nil {
+		l = WirelessLink{Interface: name, Err: err.Error()}
+	}
+	if stat, ok := quality[name]; ok {
+		l.Connected = true
+		l.Quality = stat.Quality / wirelessMaxQuality * 100
+		if l.Signal == 0 {
+			l.Signal = stat.Signal
+		}
+	} else if l.Signal != 0 {
+		// The quality cfg80211 derives from the signal
+		l.Quality = max(0, min(wirelessMaxQuality, l.Signal+110)) / wirelessMaxQuality * 100
+	}
+	return l
+}
+
+// wifiBand names the band of a frequency in MHz.
+func wifiBand(freq float64) string {
+	switch {
+	case freq >= 2400 && freq < 2500:
+		return "2.4GHz"
+	case freq >= 4900 && freq < 5925:
+		return "5GHz"
+	case freq >= 5925 && freq <= 7125:
+		return "6GHz"
+	case freq >= 58320 && freq <= 70200:
+		return "60GHz"
+	}
+	return ""
+}
+
+// wifiChannel returns the channel number of a frequency in MHz, as the
+// kernel's ieee80211_freq_khz_to_channel does, or 0 if it is unknown.
+func wifiChannel(freq float64) int {
+	f := int(freq)
+	switch {
+	case f == 2484:
+		return 14
+	case f >= 2412 && f < 2484:
+		return (f - 2407) / 5
+	case f >= 4910 && f <= 4980:
+		return (f - 4000) / 5
+	case f >= 5000 && f < 5925:
+		return (f - 5000) / 5
+	case f == 5935:
+		return 2
+	case f > 5950 && f <= 7125:
+		return (f - 5950) / 5
+	case f >= 58320 && f <= 70200:
+		return (f - 56160) / 2160
+	}
+	return 0
+}
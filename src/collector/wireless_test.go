@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWifiChannel(t *testing.T) {
+	tests := []struct {
+		freq    float64
+		channel int
+		band    string
+	}{
+		{2412, 1, "2.4GHz"},
+		{2472, 13, "2.4GHz"},
+		{2484, 14, "2.4GHz"},
+		{5180, 36, "5GHz"},
+		{5500, 100, "5GHz"},
+		{5825, 165, "5GHz"},
+		{5885, 177, "5GHz"},
+		{5935, 2, "6GHz"},
+		{5955, 1, "6GHz"},
+		{6115, 33, "6GHz"},
+		{7115, 233, "6GHz"},
+		{60480, 2, "60GHz"},
+		{0, 0, ""},
+	}
+	for _, tt := range tests {
+		if channel, band := wifiChannel(tt.freq), wifiBand(tt.freq); channel != tt.channel || band != tt.band {
+			t.Errorf("%v MHz: channel %d, band %q; want %d, %q", tt.freq, channel, band, tt.channel, tt.band)
+		}
+	}
+}
+
+func TestWirelessCollector(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewWirelessCollector(cfg)
+	c.link = func(name string) (WirelessLink, error) {
+		return WirelessLink{
+			Interface: name, Connected: true,
+			SSID: "Home Net", BSSID: "3c:22:fb:8a:11:08",
+			Frequency: 5180, Channel: 36, Band: "5GHz",
+			Signal: -56, RxBitrate: 866.7, TxBitrate: 780,
+		}, nil
+	}
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sample.(WirelessSample)
+	if len(s.Links) != 1 {
+		t.Fatalf("got %d links, want only wlan0: %+v", len(s.Links), s.Links)
+	}
+	l, ok := s.Link("wlan0")
+	if !ok || !l.Connected || l.SSID != "Home Net" || l.Band != "5GHz" || l.Signal != -56 || l.Err != "" {
+		t.Errorf("wlan0 = %+v", l)
+	}
+	// 54 out of 70
+	if l.Quality < 77 || l.Quality > 77.2 {
+		t.Errorf("Quality = %v, want 77.1", l.Quality)
+	}
+}
+
+func TestWirelessCollectorWithoutNl80211(t *testing.T) {
+	cfg := fixtureConfig(t)
+	c := NewWirelessCollector(cfg)
+	c.link = func(name string) (WirelessLink, error) {
+		return WirelessLink{}, errors.New("nl80211: not available")
+	}
+
+	sample, err := c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := sample.(WirelessSample).Link("wlan0")
+	if !l.Connected || l.Signal != -56 || l.SSID != "" || l.Band != "" || l.Err != "nl80211: not available" {
+		t.Errorf("wlan0 = %+v, want the signal of /proc/net/wireless and the error", l)
+	}
+
+	// Not in /proc/net/wireless when disconnected
+	writeFixture(t, cfg, "net/wireless", "")
+	sample, err = c.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, _ := sample.(WirelessSample).Link("wlan0"); l.Connected || l.Err == "" {
+		t.Errorf("wlan0 = %+v, want disconnected", l)
+	}
+}
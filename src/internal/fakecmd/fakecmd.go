@@ -0,0 +1,109 @@
+// Package fakecmd puts stand-ins for external commands such as nvidia-smi on
+// PATH, so tests can drive the code that runs them without the real tools.
+//
+// The stand-in is the test binary itself, linked under the command's name.
+// A package using it must call Main first thing in its TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		fakecmd.Main()
+//		os.Exit(m.Run())
+//	}
+package fakecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dirEnv holds the directory of the stand-ins of the running test.
+const dirEnv = "FAKECMD_DIR"
+
+// Response is what a stand-in does when its arguments start with Args.
+type Response struct {
+	Args     string // prefix of the space-joined arguments, "" for any
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Install makes name a stand-in for the rest of the test, answering with
+// the first matching response. Unmatched arguments exit with status 127.
+//
+// PATH is replaced, not extended, so the real command is never run and
+// any command that was not installed is missing.
+func Install(t testing.TB, name string, responses ...Response) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := os.Getenv(dirEnv)
+	if dir == "" || os.Getenv("PATH") != dir {
+		dir = EmptyPath(t)
+	}
+	if err := os.Symlink(exe, filepath.Join(dir, name)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(responses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envName(name), string(data))
+}
+
+// EmptyPath points PATH at an empty directory for the rest of the test, so
+// that every external command is missing. It returns the directory.
+func EmptyPath(t testing.TB) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	t.Setenv(dirEnv, dir)
+	return dir
+}
+
+// ReadFile returns a recorded output from the repository's testdata
+// directory, given the path of the calling package relative to it.
+func ReadFile(t testing.TB, path ...string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(append([]string{"..", "..", "testdata"}, path...)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// Main acts as the stand-in and exits when the test binary was started as
+// one. Otherwise it returns immediately.
+func Main() {
+	raw, ok := os.LookupEnv(envName(filepath.Base(os.Args[0])))
+	if !ok {
+		return
+	}
+	var responses []Response
+	if err := json.Unmarshal([]byte(raw), &responses); err != nil {
+		fmt.Fprintln(os.Stderr, "fakecmd:", err)
+		os.Exit(127)
+	}
+
+	args := strings.Join(os.Args[1:], " ")
+	for _, r := range responses {
+		if strings.HasPrefix(args, r.Args) {
+			fmt.Fprint(os.Stdout, r.Stdout)
+			fmt.Fprint(os.Stderr, r.Stderr)
+			os.Exit(r.ExitCode)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "fakecmd: no response for %q\n", args)
+	os.Exit(127)
+}
+
+// envName returns the variable holding the responses of a stand-in, e.g.
+// FAKECMD_NVIDIA_SMI.
+func envName(name string) string {
+	return "FAKECMD_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
@@ -1,7 +1,18 @@
 package server
 
 import (
+	"crypto/subtle"
+	"errors"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-test/src/collector"
+	"go-test/src/procctl"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -21,6 +32,16 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	r.GET("/health", s.healthHandler)
 
+	r.GET("/stats", s.statsHandler)
+	r.GET("/stats/stream", s.statsStreamHandler)
+	r.GET("/stats/:name", s.collectorStatsHandler)
+
+	r.GET("/disks/forecast", s.diskForecastHandler)
+
+	processes := r.Group("/processes/:pid", s.requireToken)
+	processes.POST("/signal", s.signalHandler)
+	processes.POST("/renice", s.reniceHandler)
+
 	return r
 }
 
@@ -34,3 +55,198 @@ func (s *Server) HelloWorldHandler(c *gin.Context) {
 func (s *Server) healthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, s.db.Health())
 }
+
+type sampleResponse struct {
+	Name   string           `json:"name"`
+	Time   time.Time        `json:"time"`
+	Sample collector.Sample `json:"sample,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+func newSampleResponse(u collector.Update) sampleResponse {
+	resp := sampleResponse{
+		Name:   u.Name,
+		Time:   u.Time,
+		Sample: u.Sample,
+	}
+	if u.Err != nil {
+		resp.Error = u.Err.Error()
+	}
+	return resp
+}
+
+func (s *Server) statsHandler(c *gin.Context) {
+	resp := make(map[string]sampleResponse)
+	for name, u := range s.sampler.Snapshot() {
+		resp[name] = newSampleResponse(u)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) collectorStatsHandler(c *gin.Context) {
+	u, ok := s.sampler.Latest(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no sample for " + c.Param("name")})
+		return
+	}
+
+	c.JSON(http.StatusOK, newSampleResponse(u))
+}
+
+type diskForecast struct {
+	MountPoint    string     `json:"mount_point"`
+	UsedPercent   float64    `json:"used_percent"`
+	FillRate      float64    `json:"fill_rate_bytes_per_sec"`
+	FullInSeconds *float64   `json:"full_in_seconds"`
+	FullAt        *time.Time `json:"full_at,omitempty"`
+}
+
+// diskForecastHandler lists when each real filesystem is predicted to fill
+// up, soonest first. Filesystems that are not filling up come last.
+func (s *Server) diskForecastHandler(c *gin.Context) {
+	u, ok := s.sampler.Latest(collector.Disks)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "no disk sample yet"})
+		return
+	}
+	sample, ok := u.Sample.(collector.DiskSample)
+	if u.Err != nil || !ok {
+		c.JSON(http.StatusServiceUnavailable, newSampleResponse(u))
+		return
+	}
+
+	resp := []diskForecast{}
+	for _, f := range sample.Filesystems {
+		if f.Pseudo || f.Err != "" {
+			continue
+		}
+		fc := diskForecast{
+			MountPoint:    f.MountPoint,
+			UsedPercent:   f.UsedPercent(),
+			FillRate:      f.FillRate,
+			FullInSeconds: f.FullInSeconds,
+		}
+		if d, ok := f.FullIn(); ok {
+			at := u.Time.Add(d)
+			fc.FullAt = &at
+		}
+		resp = append(resp, fc)
+	}
+	sort.SliceStable(resp, func(i, j int) bool {
+		a, b := resp[i].FullInSeconds, resp[j].FullInSeconds
+		return a != nil && (b == nil || *a < *b)
+	})
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// statsStreamHandler pushes every new sample to the client as a server-sent event.
+func (s *Server) statsStreamHandler(c *gin.Context) {
+	// The server's WriteTimeout covers the whole response, which would cut
+	// the stream after a few seconds
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates, cancel := s.sampler.Subscribe()
+	defer cancel()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case u, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent(u.Name, newSampleResponse(u))
+			return true
+		}
+	})
+}
+
+// requireToken rejects requests without a matching "Authorization: Bearer"
+// header.
+func (s *Server) requireToken(c *gin.Context) {
+	if s.apiToken == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "process actions are disabled, set API_TOKEN to enable them"})
+		return
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.apiToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing bearer token"})
+		return
+	}
+	c.Next()
+}
+
+type signalRequest struct {
+	Signal string `json:"signal" binding:"required"`
+}
+
+func (s *Server) signalHandler(c *gin.Context) {
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil || pid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pid"})
+		return
+	}
+
+	var req signalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sig, err := procctl.ParseSignal(req.Signal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := procctl.Signal(pid, sig); err != nil {
+		c.JSON(actionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pid": pid, "signal": req.Signal})
+}
+
+type reniceRequest struct {
+	Nice *int `json:"nice" binding:"required"`
+}
+
+func (s *Server) reniceHandler(c *gin.Context) {
+	pid, err := strconv.Atoi(c.Param("pid"))
+	if err != nil || pid <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pid"})
+		return
+	}
+
+	var req reniceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *req.Nice < procctl.MinNice || *req.Nice > procctl.MaxNice {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nice out of range"})
+		return
+	}
+
+	if err := procctl.Renice(pid, *req.Nice); err != nil {
+		c.JSON(actionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pid": pid, "nice": *req.Nice})
+}
+
+// actionErrorStatus maps the errno of a failed process action to an HTTP status.
+func actionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		return http.StatusForbidden
+	case errors.Is(err, syscall.ESRCH):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
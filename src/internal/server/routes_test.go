@@ -1,10 +1,18 @@
 package server
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"go-test/src/collector"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHelloWorldHandler(t *testing.T) {
@@ -30,3 +38,258 @@ func TestHelloWorldHandler(t *testing.T) {
 		t.Errorf("Handler returned unexpected body: got %v want %v", rr.Body.String(), expected)
 	}
 }
+
+type stubCollector struct{}
+
+func (stubCollector) Name() string            { return "stub" }
+func (stubCollector) Interval() time.Duration { return time.Hour }
+func (stubCollector) Collect(ctx context.Context) (collector.Sample, error) {
+	return map[string]int{"value": 42}, nil
+}
+
+func TestCollectorStatsHandler(t *testing.T) {
+	registry := collector.NewRegistry()
+	registry.MustRegister(stubCollector{})
+	s := &Server{sampler: collector.NewSampler(registry)}
+
+	updates, cancelSub := s.sampler.Subscribe()
+	defer cancelSub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.sampler.Run(ctx)
+	<-updates
+
+	r := gin.New()
+	r.GET("/stats/:name", s.collectorStatsHandler)
+
+	req, err := http.NewRequest("GET", "/stats/stub", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if !strings.Contains(rr.Body.String(), `"sample":{"value":42}`) {
+		t.Errorf("Handler returned unexpected body: got %v", rr.Body.String())
+	}
+
+	req, err = http.NewRequest("GET", "/stats/missing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
+
+func TestSignalHandler(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start a process to signal: %v", err)
+	}
+	defer cmd.Process.Kill()
+	pid := strconv.Itoa(cmd.Process.Pid)
+
+	tests := []struct {
+		name     string
+		apiToken string
+		auth     string
+		pid      string
+		body     string
+		status   int
+	}{
+		{"disabled without a token", "", "Bearer secret", pid, `{"signal":"TERM"}`, http.StatusForbidden},
+		{"missing token", "secret", "", pid, `{"signal":"TERM"}`, http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer nope", pid, `{"signal":"TERM"}`, http.StatusUnauthorized},
+		{"unknown signal", "secret", "Bearer secret", pid, `{"signal":"HUP"}`, http.StatusBadRequest},
+		{"pid zero", "secret", "Bearer secret", "0", `{"signal":"TERM"}`, http.StatusBadRequest},
+		{"negative pid", "secret", "Bearer secret", "-1", `{"signal":"TERM"}`, http.StatusBadRequest},
+		{"no such process", "secret", "Bearer secret", "99999999", `{"signal":"TERM"}`, http.StatusNotFound},
+		{"terminate", "secret", "Bearer secret", pid, `{"signal":"TERM"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{apiToken: tt.apiToken}
+			r := gin.New()
+			r.POST("/processes/:pid/signal", s.requireToken, s.signalHandler)
+
+			req, err := http.NewRequest("POST", "/processes/"+tt.pid+"/signal", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if status := rr.Code; status != tt.status {
+				t.Errorf("Handler returned wrong status code: got %v want %v (%s)", status, tt.status, rr.Body.String())
+			}
+		})
+	}
+
+	if err := cmd.Wait(); err == nil {
+		t.Errorf("process exited cleanly, want it terminated by SIGTERM")
+	}
+}
+
+func TestReniceHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		pid    string
+		body   string
+		status int
+	}{
+		{"pid zero", "0", `{"nice":5}`, http.StatusBadRequest},
+		{"negative pid", "-42", `{"nice":5}`, http.StatusBadRequest},
+		{"not a pid", "init", `{"nice":5}`, http.StatusBadRequest},
+		{"missing nice", "1", `{}`, http.StatusBadRequest},
+		{"nice out of range", "1", `{"nice":20}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{apiToken: "secret"}
+			r := gin.New()
+			r.POST("/processes/:pid/renice", s.requireToken, s.reniceHandler)
+
+			req, err := http.NewRequest("POST", "/processes/"+tt.pid+"/renice", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer secret")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if status := rr.Code; status != tt.status {
+				t.Errorf("Handler returned wrong status code: got %v want %v (%s)", status, tt.status, rr.Body.String())
+			}
+		})
+	}
+}
+
+type diskStub struct{}
+
+func (diskStub) Name() string            { return collector.Disks }
+func (diskStub) Interval() time.Duration { return time.Hour }
+func (diskStub) Collect(ctx context.Context) (collector.Sample, error) {
+	soon, later := 3600.0, 86400.0
+	return collector.DiskSample{Filesystems: []collector.FilesystemStat{
+		{MountPoint: "/", Used: 50, Free: 50},
+		{MountPoint: "/home", Used: 10, Free: 90, FillRate: 1, FullInSeconds: &later},
+		{MountPoint: "/proc", Pseudo: true},
+		{MountPoint: "/mnt/nas", Err: "statfs timed out"},
+		{MountPoint: "/var", Used: 90, Free: 10, FillRate: 100, FullInSeconds: &soon},
+	}}, nil
+}
+
+func TestDiskForecastHandler(t *testing.T) {
+	registry := collector.NewRegistry()
+	registry.MustRegister(diskStub{})
+	s := &Server{sampler: collector.NewSampler(registry)}
+
+	r := gin.New()
+	r.GET("/disks/forecast", s.diskForecastHandler)
+
+	req, err := http.NewRequest("GET", "/disks/forecast", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d before the first sample, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+
+	updates, cancelSub := s.sampler.Subscribe()
+	defer cancelSub()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.sampler.Run(ctx)
+	<-updates
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	var got []struct {
+		MountPoint    string     `json:"mount_point"`
+		FullInSeconds *float64   `json:"full_in_seconds"`
+		FullAt        *time.Time `json:"full_at"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, f := range got {
+		order = append(order, f.MountPoint)
+	}
+	if strings.Join(order, " ") != "/var /home /" {
+		t.Errorf("forecasts in order %v, want /var /home /", order)
+	}
+	if got[0].FullAt == nil || got[2].FullAt != nil || got[2].FullInSeconds != nil {
+		t.Errorf("unexpected forecast fields: %s", rr.Body.String())
+	}
+}
+
+type tickStub struct{}
+
+func (tickStub) Name() string            { return "tick" }
+func (tickStub) Interval() time.Duration { return 50 * time.Millisecond }
+func (tickStub) Collect(ctx context.Context) (collector.Sample, error) {
+	return map[string]int{"value": 1}, nil
+}
+
+func TestStatsStreamHandler(t *testing.T) {
+	registry := collector.NewRegistry()
+	registry.MustRegister(tickStub{})
+	s := &Server{sampler: collector.NewSampler(registry)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.sampler.Run(ctx)
+
+	r := gin.New()
+	r.GET("/stats/stream", s.statsStreamHandler)
+	srv := httptest.NewUnstartedServer(r)
+	// The stream must outlive the server's WriteTimeout
+	srv.Config.WriteTimeout = 200 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stats/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	start := time.Now()
+	lines := bufio.NewScanner(resp.Body)
+	events := 0
+	for time.Since(start) < 3*srv.Config.WriteTimeout && lines.Scan() {
+		if line := lines.Text(); line == "event:tick" {
+			events++
+		} else if strings.HasPrefix(line, "data:") && !strings.Contains(line, `"sample":{"value":1}`) {
+			t.Errorf("unexpected event data %q", line)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		t.Fatalf("stream broken after %v and %d events: %v", time.Since(start), events, err)
+	}
+	if time.Since(start) < 3*srv.Config.WriteTimeout {
+		t.Fatalf("stream closed after %v and %d events", time.Since(start), events)
+	}
+	if events < 2 {
+		t.Errorf("got %d events, want one per sample", events)
+	}
+}
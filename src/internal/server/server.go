@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,13 +10,20 @@ import (
 
 	_ "github.com/joho/godotenv/autoload"
 
-	"go-test/internal/database"
+	"go-test/src/collector"
+	"go-test/src/internal/database"
 )
 
 type Server struct {
 	port int
 
 	db database.Service
+
+	sampler *collector.Sampler
+
+	// apiToken guards endpoints that act on the host; they are disabled
+	// when it is empty.
+	apiToken string
 }
 
 func NewServer() *http.Server {
@@ -24,16 +32,31 @@ func NewServer() *http.Server {
 		port: port,
 
 		db: database.New(),
+
+		sampler: collector.NewSampler(collector.NewDefaultRegistry(collector.DefaultConfig())),
+
+		apiToken: os.Getenv("API_TOKEN"),
 	}
 
+	return NewServer.httpServer()
+}
+
+// httpServer serves the routes and samples until the server is shut down.
+func (s *Server) httpServer() *http.Server {
+	ctx, stop := context.WithCancel(context.Background())
+	go s.sampler.Run(ctx)
+
 	// Declare Server config
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", NewServer.port),
-		Handler:      NewServer.RegisterRoutes(),
+		Addr:         fmt.Sprintf(":%d", s.port),
+		Handler:      s.RegisterRoutes(),
 		IdleTimeout:  time.Minute,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+	// Stopping the sampler also ends the event streams, which Shutdown
+	// would otherwise wait for
+	server.RegisterOnShutdown(stop)
 
 	return server
 }
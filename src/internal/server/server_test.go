@@ -0,0 +1,56 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test/src/collector"
+)
+
+func TestShutdownStopsSampler(t *testing.T) {
+	registry := collector.NewRegistry()
+	registry.MustRegister(tickStub{})
+	s := &Server{sampler: collector.NewSampler(registry)}
+	server := s.httpServer()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(ln)
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/stats/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body := bufio.NewReader(resp.Body)
+	if line, err := body.ReadString('\n'); err != nil || !strings.HasPrefix(line, "event:") {
+		t.Fatalf("first line = %q, %v, want an event", line, err)
+	}
+
+	updates, _ := s.sampler.Subscribe()
+
+	// The open stream only ends if Shutdown stops the sampler
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	// The sampler closes its subscriptions when it stops
+	for {
+		select {
+		case _, ok := <-updates:
+			if !ok {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("sampler still running after Shutdown")
+		}
+	}
+}
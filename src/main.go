@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"go-test/src/collector"
 	"go-test/src/models"
 	"os"
 
@@ -9,7 +11,13 @@ import (
 )
 
 func main() {
-	p := tea.NewProgram(models.InitialModel(), tea.WithAltScreen())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sampler := collector.NewSampler(collector.NewDefaultRegistry(collector.DefaultConfig()))
+	go sampler.Run(ctx)
+
+	p := tea.NewProgram(models.InitialModel(sampler), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-test/src/collector"
+	"go-test/src/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const connectionsPageSize = 12
+
+type ConnectionsModel struct {
+	Connections collector.ConnectionsSample
+	Err         error
+	Loaded      bool
+
+	ListeningOnly bool // show the listening ports view
+	offset        int  // first visible row
+}
+
+func NewConnectionsModel() ConnectionsModel {
+	return ConnectionsModel{}
+}
+
+func (m ConnectionsModel) Update(msg tea.Msg) (ConnectionsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SampleMsg:
+		if msg.Name != collector.Connections {
+			return m, nil
+		}
+		m.Loaded = true
+		m.Err = msg.Err
+		m.Connections, _ = msg.Sample.(collector.ConnectionsSample)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			m.offset--
+		case "down", "j":
+			m.offset++
+		case "pgup":
+			m.offset -= connectionsPageSize
+		case "pgdown":
+			m.offset += connectionsPageSize
+		case "home", "g":
+			m.offset = 0
+		case "l":
+			m.ListeningOnly = !m.ListeningOnly
+			m.offset = 0
+		}
+	}
+	m.offset = max(0, min(m.offset, len(m.Rows())-connectionsPageSize))
+	return m, nil
+}
+
+// Rows returns the connections to list, listening sockets first.
+func (m ConnectionsModel) Rows() []collector.Connection {
+	if !m.ListeningOnly {
+		return m.Connections.Connections
+	}
+	var rows []collector.Connection
+	for _, c := range m.Connections.Connections {
+		if c.Listening() {
+			rows = append(rows, c)
+		}
+	}
+	return rows
+}
+
+func (m ConnectionsModel) View() string {
+	title := styles.TitleStyle.Render("CONNECTIONS")
+
+	var content string
+	switch {
+	case !m.Loaded:
+		content = styles.StatKeyStyle.Render("Loading...")
+	case m.Err != nil:
+		content = styles.StatValueStyle.Foreground(styles.ColorError).Render(m.Err.Error())
+	default:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			m.renderStates(),
+			"",
+			m.renderTable(),
+			"",
+			m.renderHelp(),
+		)
+	}
+
+	box := styles.StatBoxStyle.Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, box)
+}
+
+// renderStates counts the TCP sockets per state, most common first.
+func (m ConnectionsModel) renderStates() string {
+	states := make([]string, 0, len(m.Connections.States))
+	for state := range m.Connections.States {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		a, b := m.Connections.States[states[i]], m.Connections.States[states[j]]
+		if a != b {
+			return a > b
+		}
+		return states[i] < states[j]
+	})
+
+	counts := make([]string, len(states))
+	for i, state := range states {
+		counts[i] = fmt.Sprintf("%s %d", state, m.Connections.States[state])
+	}
+	if len(counts) == 0 {
+		counts = []string{"None"}
+	}
+	return styles.RenderStat("󰌘 TCP States:", truncate(strings.Join(counts, " • "), 52))
+}
+
+func (m ConnectionsModel) renderTable() string {
+	header := styles.TableHeaderStyle.Padding(0).Render(fmt.Sprintf("%-5s %-24s %-24s %-11s %-16s", "PROTO", "LOCAL", "REMOTE", "STATE", "PROCESS"))
+	lines := []string{header}
+
+	rows := m.Rows()
+	end := min(m.offset+connectionsPageSize, len(rows))
+	for _, c := range rows[m.offset:end] {
+		process := "-"
+		if c.Pid != 0 {
+			process = strconv.Itoa(c.Pid) + "/" + c.Process
+		}
+		row := fmt.Sprintf("%-5s %-24s %-24s ", c.Protocol, truncate(c.Local(), 24), truncate(c.Remote(), 24))
+		state := styles.StatValueStyle.Foreground(connectionStateColor(c.State)).Render(fmt.Sprintf("%-11s", truncate(c.State, 11)))
+		lines = append(lines, styles.TableCellStyle.Padding(0).Render(row)+state+styles.TableCellStyle.Padding(0).Render(" "+truncate(process, 16)))
+	}
+	if len(rows) == 0 {
+		lines = append(lines, styles.StatKeyStyle.Render("No connections..."))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func connectionStateColor(state string) lipgloss.Color {
+	switch state {
+	case "LISTEN", "UNCONN":
+		return styles.ColorCyan
+	case "ESTABLISHED":
+		return styles.ColorSuccess
+	case "TIME_WAIT", "CLOSE_WAIT", "FIN_WAIT1", "FIN_WAIT2", "LAST_ACK", "CLOSING":
+		return styles.ColorSubtext
+	}
+	return styles.ColorWarning
+}
+
+func (m ConnectionsModel) renderHelp() string {
+	rows := len(m.Rows())
+	position := fmt.Sprintf("%d-%d/%d", min(m.offset+1, rows), min(m.offset+connectionsPageSize, rows), rows)
+
+	help := "[j/k] Scroll • [PgUp/PgDn] Page • [l] Listening ports"
+	if m.ListeningOnly {
+		help = "[j/k] Scroll • [PgUp/PgDn] Page • [l] All connections"
+	}
+	return styles.HelpStyle.Margin(0).Render(position + "  " + help)
+}
@@ -2,89 +2,63 @@ package models
 
 import (
 	"fmt"
-	"os/exec"
-	"regexp"
-	"time"
+	"slices"
+	"sort"
+	"strings"
 
+	"go-test/src/collector"
+	"go-test/src/procfs"
 	"go-test/src/styles"
 
 	tea "github.com/charmbracelet/bubbletea"
 	lipgloss "github.com/charmbracelet/lipgloss"
-	cpu "github.com/shirou/gopsutil/v3/cpu"
-	host "github.com/shirou/gopsutil/v3/host"
-	mem "github.com/shirou/gopsutil/v3/mem"
 )
 
-type CpuStatsMsg struct {
-	id          int
-	cpuName     string
-	cpuFreq     float64
-	cpuUsage    float64
-	cpuTemp     float64
-	cpuFanSpeed string
-
-	ramTotal       string
-	ramUsed        string
-	ramFree        string
-	ramUsedPercent string
-	ramFreePercent string
-}
-
 type CpuModel struct {
-	Id          int
-	CpuName     string
-	CpuFreq     float64
-	CpuUsage    float64
-	CpuTemp     float64
-	CpuFanSpeed string
-
-	RamTotal       string
-	RamUsed        string
-	RamFree        string
-	RamUsedPercent string
-	RamFreePercent string
-
-	Polling bool
+	CpuName      string
+	CpuFreq      float64
+	CpuUsage     float64
+	CpuBreakdown collector.CpuBreakdown
+	CpuCores     []collector.CoreSample
+	CpuTemp      float64
+	CpuFanSpeed  string
+
+	Load    collector.LoadSample
+	LoadErr error
+
+	Pressure    collector.PressureSample
+	PressureErr error
 }
 
 func NewCpuModel() CpuModel {
 	return CpuModel{
-		Id:             0,
-		CpuName:        "Loading...",
-		CpuFanSpeed:    "Loading...",
-		RamTotal:       "Loading...",
-		RamUsed:        "Loading...",
-		RamFree:        "Loading...",
-		RamUsedPercent: "0%",
-		RamFreePercent: "0%",
-	}
-}
-
-func (m CpuModel) Init() tea.Cmd {
-	if m.Polling {
-		return getCpuStats(m.Id)
+		CpuName:     "Loading...",
+		CpuFanSpeed: "Loading...",
 	}
-	return nil
 }
 
 func (m CpuModel) Update(msg tea.Msg) (CpuModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case CpuStatsMsg:
-		if msg.id != m.Id {
-			return m, nil
-		}
-		m.CpuName = msg.cpuName
-		m.CpuFreq = msg.cpuFreq
-		m.CpuUsage = msg.cpuUsage
-		m.CpuTemp = msg.cpuTemp
-		m.CpuFanSpeed = msg.cpuFanSpeed
-		m.RamTotal = msg.ramTotal
-		m.RamUsed = msg.ramUsed
-		m.RamFree = msg.ramFree
-		m.RamUsedPercent = msg.ramUsedPercent
-		m.RamFreePercent = msg.ramFreePercent
-		if m.Polling {
-			return m, getCpuStats(m.Id)
+	case SampleMsg:
+		switch msg.Name {
+		case collector.Cpu:
+			cpu, ok := msg.Sample.(collector.CpuSample)
+			if msg.Err != nil || !ok {
+				cpu = collector.CpuSample{Name: "N/A", FanSpeed: "N/A"}
+			}
+			m.CpuName = cpu.Name
+			m.CpuFreq = cpu.FreqMHz
+			m.CpuUsage = cpu.Usage
+			m.CpuBreakdown = cpu.Breakdown
+			m.CpuCores = cpu.Cores
+			m.CpuTemp = cpu.Temp
+			m.CpuFanSpeed = cpu.FanSpeed
+		case collector.Load:
+			m.Load, _ = msg.Sample.(collector.LoadSample)
+			m.LoadErr = msg.Err
+		case collector.Pressure:
+			m.Pressure, _ = msg.Sample.(collector.PressureSample)
+			m.PressureErr = msg.Err
 		}
 	}
 	return m, nil
@@ -114,12 +88,14 @@ func (m CpuModel) View() string {
 		styles.RenderStat(" Model:", modelInfo),
 		"",
 		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render("󰾆 Usage:"), usageStr),
+		styles.RenderStat("", renderBreakdown(m.CpuBreakdown)),
 		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render(" Temp:"), tempStr),
 		styles.RenderStat("󰜮 Fan Speed:", m.CpuFanSpeed),
+		m.renderLoad(),
+		"",
+		renderCoreGrid(m.CpuCores),
 		"",
-		styles.RenderStat(" RAM Total:", m.RamTotal+" MiB"),
-		styles.RenderStat(fmt.Sprintf(" RAM Used (%s):", m.RamUsedPercent), m.RamUsed+" MiB"),
-		styles.RenderStat(fmt.Sprintf(" RAM Free (%s):", m.RamFreePercent), m.RamFree+" MiB"),
+		m.renderPressure(),
 	)
 
 	box := styles.StatBoxStyle.Render(content)
@@ -128,97 +104,140 @@ func (m CpuModel) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, title, box)
 }
 
-func collectCpuData(id int) tea.Msg {
-	var cpuName string
-	var cpuFreq float64
-	var cpuUsage float64
-	var cpuTemp float64
+// renderLoad shows the load averages, coloured against the number of cores,
+// and the scheduler activity.
+func (m CpuModel) renderLoad() string {
+	if m.LoadErr != nil {
+		return styles.RenderStat("Load (1/5/15):", "N/A")
+	}
+
+	loadColor := styles.ColorSuccess
+	if cores := float64(len(m.CpuCores)); cores > 0 {
+		if m.Load.Load1 > cores {
+			loadColor = styles.ColorError
+		} else if m.Load.Load1 > cores*0.7 {
+			loadColor = styles.ColorWarning
+		}
+	}
+	loadStr := styles.StatValueStyle.Foreground(loadColor).Render(fmt.Sprintf("%.2f %.2f %.2f", m.Load.Load1, m.Load.Load5, m.Load.Load15))
 
-	var cpuFanSpeed string = "N/A"
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render("Load (1/5/15):"), loadStr),
+		styles.RenderStat("Tasks:", fmt.Sprintf("%d running, %d blocked, %d total", m.Load.Running, m.Load.Blocked, m.Load.Tasks)),
+		styles.RenderStat("Context Switches / Interrupts:", fmt.Sprintf("%.0f/s / %.0f/s", m.Load.ContextSwitchesPerSec, m.Load.InterruptsPerSec)),
+	)
+}
 
-	var ramTotal string
-	var ramUsed string
-	var ramFree string
+// pressureCgroupRows is how many of the most stalled cgroups are listed.
+const pressureCgroupRows = 3
 
-	// ... (cpu/mem logic is unchanged, I am targeting surrounding lines to insert)
+// renderPressure shows the stall averages per resource, the cgroups under
+// the most pressure and any threshold that was crossed.
+func (m CpuModel) renderPressure() string {
+	if m.PressureErr != nil || m.Pressure.Pressure == nil {
+		return styles.RenderStat("Pressure (PSI):", "N/A")
+	}
 
-	// Collect Fan Speed via sensors
-	out, errSensors := exec.Command("sensors").Output()
-	if errSensors == nil {
-		re := regexp.MustCompile(`cpu_fan:\s+(\d+\s+RPM)`)
-		matches := re.FindStringSubmatch(string(out))
-		if len(matches) > 1 {
-			cpuFanSpeed = matches[1]
+	lines := []string{styles.RenderStat("Pressure (PSI):", "some 10s/60s/300s    full 10s/60s/300s")}
+	for _, res := range collector.PressureResources {
+		lines = append(lines, styles.RenderStat("  "+res+":", formatPSI(m.Pressure.Pressure[res])))
+	}
+
+	cgroups := slices.Clone(m.Pressure.Cgroups)
+	sort.SliceStable(cgroups, func(i, j int) bool {
+		return maxSomePressure(cgroups[i]) > maxSomePressure(cgroups[j])
+	})
+	for _, cg := range cgroups[:min(pressureCgroupRows, len(cgroups))] {
+		var parts []string
+		for _, res := range collector.PressureResources {
+			if stats, ok := cg.Pressure[res]; ok {
+				parts = append(parts, fmt.Sprintf("%s %.1f", res, stats.Some.Avg10))
+			}
 		}
+		lines = append(lines, styles.RenderStat("  "+truncate(cg.Path, 28)+":", strings.Join(parts, "  ")))
 	}
 
-	percent, err := cpu.Percent(0, false)
-	if err != nil {
-		cpuUsage = 0
-	} else {
-		cpuUsage = percent[0]
-	}
-
-	cpuInfo, err := cpu.Info()
-	if err != nil {
-		cpuName = "N/A"
-		cpuFreq = 0
-	} else {
-		if len(cpuInfo) > 0 {
-			cpuName = cpuInfo[0].ModelName
-			cpuFreq = cpuInfo[0].Mhz
-		} else {
-			cpuName = "Unknown"
-			cpuFreq = 0
+	for _, a := range m.Pressure.Alerts {
+		where := "system"
+		if a.Cgroup != "" {
+			where = a.Cgroup
 		}
+		lines = append(lines, styles.StatValueStyle.Foreground(styles.ColorError).Render(
+			fmt.Sprintf("⚠ %s: %s (%.1f%%)", where, a.Threshold, a.Value)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func formatPSI(s procfs.PSIStats) string {
+	full := "-"
+	if s.Full != nil {
+		full = fmt.Sprintf("%.1f/%.1f/%.1f", s.Full.Avg10, s.Full.Avg60, s.Full.Avg300)
 	}
+	return fmt.Sprintf("%-20s %s", fmt.Sprintf("%.1f/%.1f/%.1f", s.Some.Avg10, s.Some.Avg60, s.Some.Avg300), full)
+}
 
-	tempdata, err := host.SensorsTemperatures()
-	if err != nil || len(tempdata) == 0 {
-		cpuTemp = 0
-	} else {
-		cpuTemp = tempdata[0].Temperature
-	}
-
-	var ramUsedPercent string
-	var ramFreePercent string
-
-	vmStat, err := mem.VirtualMemory()
-	if err != nil {
-		ramTotal = "N/A"
-		ramUsed = "N/A"
-		ramFree = "N/A"
-		ramUsedPercent = "0%"
-		ramFreePercent = "0%"
-	} else {
-		ramTotal = fmt.Sprintf("%.2f", float64(vmStat.Total)/1024/1024) // in MiB
-		ramUsed = fmt.Sprintf("%.2f", float64(vmStat.Used)/1024/1024)
-		ramFree = fmt.Sprintf("%.2f", float64(vmStat.Free)/1024/1024)
-
-		usedP := float64(vmStat.Used) / float64(vmStat.Total) * 100
-		freeP := float64(vmStat.Free) / float64(vmStat.Total) * 100
-		ramUsedPercent = fmt.Sprintf("%.0f%%", usedP)
-		ramFreePercent = fmt.Sprintf("%.0f%%", freeP)
-	}
-
-	return CpuStatsMsg{
-		id:          id,
-		cpuName:     cpuName,
-		cpuFreq:     cpuFreq,
-		cpuUsage:    cpuUsage,
-		cpuTemp:     cpuTemp,
-		cpuFanSpeed: cpuFanSpeed,
-
-		ramTotal:       ramTotal,
-		ramUsed:        ramUsed,
-		ramFree:        ramFree,
-		ramUsedPercent: ramUsedPercent,
-		ramFreePercent: ramFreePercent,
+func maxSomePressure(cg collector.CgroupPressure) float64 {
+	var v float64
+	for _, stats := range cg.Pressure {
+		v = max(v, stats.Some.Avg10)
 	}
+	return v
 }
 
-func getCpuStats(id int) tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return collectCpuData(id)
-	})
+// renderBreakdown shows the time in each mode, in percent.
+func renderBreakdown(b collector.CpuBreakdown) string {
+	return styles.HelpStyle.Margin(0).Render(fmt.Sprintf("usr %.1f  sys %.1f  io %.1f  irq %.1f  st %.1f",
+		b.User, b.System, b.Iowait, b.IRQ, b.Steal))
+}
+
+// Three cells per row fill the inner width of StatBoxStyle.
+const (
+	coreCellWidth = 28
+	coresPerRow   = 3
+)
+
+// renderCoreGrid lays out a usage bar per logical core, grouped by core type
+// on hybrid CPUs.
+func renderCoreGrid(cores []collector.CoreSample) string {
+	if len(cores) == 0 {
+		return styles.StatKeyStyle.Render("No per-core data...")
+	}
+
+	var groups []string
+	var order []string
+	byType := make(map[string][]collector.CoreSample)
+	for _, c := range cores {
+		if _, ok := byType[c.Type]; !ok {
+			order = append(order, c.Type)
+		}
+		byType[c.Type] = append(byType[c.Type], c)
+	}
+	// P-cores first, then E-cores
+	sort.Strings(order)
+	slices.Reverse(order)
+
+	for _, typ := range order {
+		group := byType[typ]
+		var lines []string
+		if typ != "" {
+			lines = append(lines, styles.StatKeyStyle.Render(fmt.Sprintf("%ss (%d):", typ, len(group))))
+		}
+		for i := 0; i < len(group); i += coresPerRow {
+			var cells []string
+			for _, c := range group[i:min(i+coresPerRow, len(group))] {
+				cells = append(cells, lipgloss.NewStyle().Width(coreCellWidth).Render(renderCore(c)))
+			}
+			lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+		}
+		groups = append(groups, lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, groups...)
+}
+
+func renderCore(c collector.CoreSample) string {
+	freq := "  N/A"
+	if c.FreqMHz > 0 {
+		freq = fmt.Sprintf("%4.1fG", c.FreqMHz/1000)
+	}
+	return fmt.Sprintf("%3d %s %5.1f%% %s", c.ID, styles.RenderProgressBar(8, c.Usage), c.Usage, freq)
 }
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go-test/src/collector"
+	"go-test/src/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type DiskModel struct {
+	Disks  collector.DiskSample
+	Err    error
+	Loaded bool
+
+	ShowPseudo bool // list pseudo filesystems and virtual block devices
+}
+
+func NewDiskModel() DiskModel {
+	return DiskModel{}
+}
+
+func (m DiskModel) Update(msg tea.Msg) (DiskModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SampleMsg:
+		if msg.Name != collector.Disks {
+			return m, nil
+		}
+		m.Loaded = true
+		m.Err = msg.Err
+		m.Disks, _ = msg.Sample.(collector.DiskSample)
+	case tea.KeyMsg:
+		if msg.String() == "p" {
+			m.ShowPseudo = !m.ShowPseudo
+		}
+	}
+	return m, nil
+}
+
+// Filesystems returns the filesystems to show, in mount order.
+func (m DiskModel) Filesystems() []collector.FilesystemStat {
+	var fs []collector.FilesystemStat
+	for _, f := range m.Disks.Filesystems {
+		if m.ShowPseudo || !f.Pseudo {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// Devices returns the block devices to show.
+func (m DiskModel) Devices() []collector.BlockDeviceStat {
+	var devices []collector.BlockDeviceStat
+	for _, d := range m.Disks.Devices {
+		if m.ShowPseudo || !d.Pseudo {
+			devices = append(devices, d)
+		}
+	}
+	return devices
+}
+
+func (m DiskModel) View() string {
+	title := styles.TitleStyle.Render("DISKS")
+
+	var content string
+	switch {
+	case !m.Loaded:
+		content = styles.StatKeyStyle.Render("Loading...")
+	case m.Err != nil:
+		content = styles.StatValueStyle.Foreground(styles.ColorError).Render(m.Err.Error())
+	default:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			m.renderFilesystems(),
+			"",
+			m.renderDevices(),
+		)
+	}
+
+	help := "[p] Show pseudo filesystems"
+	if m.ShowPseudo {
+		help = "[p] Hide pseudo filesystems"
+	}
+	if hidden := len(m.Disks.Filesystems) - len(m.Filesystems()); hidden > 0 {
+		help = fmt.Sprintf("%d hidden • %s", hidden, help)
+	}
+	content = lipgloss.JoinVertical(lipgloss.Left, content, "", styles.HelpStyle.Margin(0).Render(help))
+
+	box := styles.StatBoxStyle.Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, box)
+}
+
+func (m DiskModel) renderFilesystems() string {
+	header := styles.TableHeaderStyle.Padding(0).Render(fmt.Sprintf("%-13s %-6s %9s %10s %10s %5s %7s  %s", "MOUNT", "TYPE", "SIZE", "USED", "AVAIL", "INODE", "FULL IN", "USE%"))
+	lines := []string{header}
+
+	for _, f := range m.Filesystems() {
+		if f.Err != "" {
+			row := fmt.Sprintf("%-13s %-6s ", truncate(f.MountPoint, 13), truncate(f.FSType, 6))
+			unavailable := styles.StatValueStyle.Foreground(styles.ColorError).Render(truncate("Unavailable: "+f.Err, 63))
+			lines = append(lines, styles.TableCellStyle.Padding(0).Render(row)+unavailable)
+			continue
+		}
+		used := f.UsedPercent()
+		usage := styles.RenderProgressBar(8, used) + fmt.Sprintf(" %3.0f%%", used)
+		row := fmt.Sprintf("%-13s %-6s %9s %10s %10s %4.0f%% %7s  ",
+			truncate(f.MountPoint, 13), truncate(f.FSType, 6),
+			formatSize(f.Size), formatSize(f.Used), formatSize(f.Free), f.InodesUsedPercent(), formatFullIn(f))
+		lines = append(lines, styles.TableCellStyle.Padding(0).Render(row)+usage)
+	}
+	if len(lines) == 1 {
+		lines = append(lines, styles.StatKeyStyle.Render("No filesystems..."))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// formatFullIn shortens the fill-rate forecast of f to fit its column.
+func formatFullIn(f collector.FilesystemStat) string {
+	d, ok := f.FullIn()
+	switch {
+	case !ok:
+		return "-"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+	return ">1y"
+}
+
+func (m DiskModel) renderDevices() string {
+	header := styles.TableHeaderStyle.Padding(0).Render(fmt.Sprintf("%-12s %12s %12s %7s %7s %6s %13s", "DEVICE", "READ", "WRITE", "R IOPS", "W IOPS", "UTIL", "LAT R/W ms"))
+	lines := []string{header}
+
+	for _, d := range m.Devices() {
+		utilColor := styles.ColorSuccess
+		if d.Utilization > 50 {
+			utilColor = styles.ColorWarning
+		}
+		if d.Utilization > 90 {
+			utilColor = styles.ColorError
+		}
+		row := fmt.Sprintf("%-12s %12s %12s %7.0f %7.0f ",
+			truncate(d.Name, 12), formatSpeed(d.ReadBytesPerSec), formatSpeed(d.WriteBytesPerSec), d.ReadIOPS, d.WriteIOPS)
+		util := styles.StatValueStyle.Foreground(utilColor).Render(fmt.Sprintf("%5.1f%%", d.Utilization))
+		latency := fmt.Sprintf(" %13s", fmt.Sprintf("%.1f/%.1f", d.ReadLatencyMs, d.WriteLatencyMs))
+		lines = append(lines, styles.TableCellStyle.Padding(0).Render(row)+util+styles.TableCellStyle.Padding(0).Render(latency))
+	}
+	if len(lines) == 1 {
+		lines = append(lines, styles.StatKeyStyle.Render("No block devices..."))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
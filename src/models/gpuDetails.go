@@ -1,86 +1,49 @@
 package models
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
-	"regexp"
-	"strconv"
 	"strings"
-	"time"
 
+	"go-test/src/collector"
 	styles "go-test/src/styles"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type GpuStatsMsg struct {
-	id                   int
-	gpuName              string
-	gpuUsage             string
-	gpuTemp              string
-	gpuFans              string
-	gpuMemoryTotal       string
-	gpuMemoryUsed        string
-	gpuMemoryFree        string
-	gpuMemoryUsedPercent string
-	gpuMemoryFreePercent string
-}
-
 type GpuModel struct {
-	Id                   int
-	GpuName              string
-	GpuUsage             string
-	GpuTemp              string
-	GpuFans              string
-	GpuMemoryTotal       string
-	GpuMemoryUsed        string
-	GpuMemoryFree        string
-	GpuMemoryUsedPercent string
-	GpuMemoryFreePercent string
-
-	Polling bool
-}
+	Gpus   []collector.GpuDevice
+	Err    error
+	Loaded bool
 
-func NewGpuModel() GpuModel {
-	return GpuModel{
-		Id:                   0,
-		GpuName:              "Loading...",
-		GpuUsage:             "Loading...",
-		GpuTemp:              "Loading...",
-		GpuFans:              "Loading...",
-		GpuMemoryTotal:       "Loading...",
-		GpuMemoryUsed:        "Loading...",
-		GpuMemoryFree:        "Loading...",
-		GpuMemoryUsedPercent: "0%",
-		GpuMemoryFreePercent: "0%",
-	}
+	Selected int // index into Gpus of the card shown
 }
 
-func (m GpuModel) Init() tea.Cmd {
-	if m.Polling {
-		return getGpuStats(m.Id)
-	}
-	return nil
+func NewGpuModel() GpuModel {
+	return GpuModel{}
 }
 
 func (m GpuModel) Update(msg tea.Msg) (GpuModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case GpuStatsMsg:
-		if msg.id != m.Id {
+	case SampleMsg:
+		if msg.Name != collector.Gpu {
+			return m, nil
+		}
+		m.Loaded = true
+		m.Err = msg.Err
+		gpus, _ := msg.Sample.(collector.GpuSample)
+		m.Gpus = gpus.Gpus
+		m.Selected = max(0, min(m.Selected, len(m.Gpus)-1))
+	case tea.KeyMsg:
+		if len(m.Gpus) < 2 {
 			return m, nil
 		}
-		m.GpuName = msg.gpuName
-		m.GpuUsage = msg.gpuUsage
-		m.GpuTemp = msg.gpuTemp
-		m.GpuFans = msg.gpuFans
-		m.GpuMemoryTotal = msg.gpuMemoryTotal
-		m.GpuMemoryUsed = msg.gpuMemoryUsed
-		m.GpuMemoryFree = msg.gpuMemoryFree
-		m.GpuMemoryUsedPercent = msg.gpuMemoryUsedPercent
-		m.GpuMemoryFreePercent = msg.gpuMemoryFreePercent
-		if m.Polling {
-			return m, getGpuStats(m.Id)
+		switch msg.String() {
+		case "]":
+			m.Selected = (m.Selected + 1) % len(m.Gpus)
+		case "[":
+			m.Selected = (m.Selected + len(m.Gpus) - 1) % len(m.Gpus)
 		}
 	}
 	return m, nil
@@ -89,115 +52,134 @@ func (m GpuModel) Update(msg tea.Msg) (GpuModel, tea.Cmd) {
 func (m GpuModel) View() string {
 	title := styles.TitleStyle.Render("GPU DETAILS")
 
-	// Parse temp for color
-	tempVal, _ := strconv.ParseFloat(m.GpuTemp, 64)
-	tempColor := styles.GetTempColor(tempVal)
-	tempIcon := styles.GetTempIcon(tempVal)
-	tempStr := styles.StatValueStyle.Foreground(tempColor).Render(fmt.Sprintf("%s %s°C", tempIcon, m.GpuTemp))
-
-	usageVal, _ := strconv.ParseFloat(m.GpuUsage, 64)
-	progress := styles.RenderProgressBar(20, usageVal)
-	usageStr := fmt.Sprintf("%s %s%%", progress, m.GpuUsage)
-
-	fanStr := m.GpuFans
-	if !strings.Contains(fanStr, "RPM") && !strings.Contains(fanStr, "N/A") {
-		fanStr += "%"
+	var content string
+	switch {
+	case !m.Loaded:
+		content = styles.RenderStat("󰍛 Model:", "Loading...")
+	case m.Err != nil || len(m.Gpus) == 0:
+		name := "N/A"
+		if errors.Is(m.Err, collector.ErrGpuParse) {
+			name = "Error parsing"
+		}
+		content = styles.RenderStat("󰍛 Model:", name)
+	case len(m.Gpus) == 1:
+		content = renderGpu(m.Gpus[0])
+	default:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			m.renderSelector(),
+			"",
+			renderGpu(m.Gpus[m.Selected]),
+			"",
+			styles.HelpStyle.Margin(0).Render("[[ / ]] Switch GPU"),
+		)
 	}
 
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		styles.RenderStat("󰍛 Model:", m.GpuName),
-		"",
-		styles.RenderStat("󰾆 Usage:", usageStr),
-		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render(" Temp:"), tempStr),
-		styles.RenderStat("󰜮 Fans:", fanStr),
-		"",
-		styles.RenderStat(" Memory Total:", m.GpuMemoryTotal+" MiB"),
-		styles.RenderStat(fmt.Sprintf(" Memory Used (%s):", m.GpuMemoryUsedPercent), m.GpuMemoryUsed+" MiB"),
-		styles.RenderStat(fmt.Sprintf(" Memory Free (%s):", m.GpuMemoryFreePercent), m.GpuMemoryFree+" MiB"),
-	)
-
 	box := styles.StatBoxStyle.Render(content)
 	// help := styles.HelpStyle.Render("[Space] Return to Menu")
 
 	return lipgloss.JoinVertical(lipgloss.Left, title, box)
 }
 
-func collectNvidiaData(id int) tea.Msg {
-	// Optimization: Fetch all data in one command
-	cmd := exec.Command("nvidia-smi", "--query-gpu=name,utilization.gpu,temperature.gpu,fan.speed,memory.total,memory.used,memory.free", "--format=csv,noheader,nounits")
-	out, err := cmd.Output()
-	if err != nil {
-		return GpuStatsMsg{
-			id:                   id,
-			gpuName:              "N/A",
-			gpuUsage:             "N/A",
-			gpuTemp:              "N/A",
-			gpuFans:              "N/A",
-			gpuMemoryTotal:       "N/A",
-			gpuMemoryUsed:        "N/A",
-			gpuMemoryFree:        "N/A",
-			gpuMemoryUsedPercent: "0%",
-			gpuMemoryFreePercent: "0%",
+// renderSelector lists every GPU by card, highlighting the one shown.
+// Indexes alone repeat when NVIDIA and DRM GPUs are mixed.
+func (m GpuModel) renderSelector() string {
+	tabs := make([]string, len(m.Gpus))
+	for i, g := range m.Gpus {
+		label := fmt.Sprintf(" %s: %s ", g.Card, truncate(strings.TrimPrefix(g.Name, "NVIDIA "), 16))
+		if i == m.Selected {
+			tabs[i] = styles.TableSelectedStyle.Render(label)
+		} else {
+			tabs[i] = styles.StatKeyStyle.Width(0).Render(label)
 		}
 	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
 
-	csv := strings.TrimSpace(string(out))
-	fields := strings.Split(csv, ", ")
-
-	if len(fields) < 7 {
-		return GpuStatsMsg{
-			id:                   id,
-			gpuName:              "Error parsing",
-			gpuUsage:             "N/A",
-			gpuTemp:              "N/A",
-			gpuFans:              "N/A",
-			gpuMemoryTotal:       "N/A",
-			gpuMemoryUsed:        "N/A",
-			gpuMemoryFree:        "N/A",
-			gpuMemoryUsedPercent: "0%",
-			gpuMemoryFreePercent: "0%",
-		}
-	}
+// renderGpu renders the card of one GPU.
+func renderGpu(g collector.GpuDevice) string {
+	tempColor := styles.GetTempColor(g.Temp)
+	tempIcon := styles.GetTempIcon(g.Temp)
+	tempStr := styles.StatValueStyle.Foreground(tempColor).Render(fmt.Sprintf("%s %.0f°C", tempIcon, g.Temp))
 
-	gpuFans := fields[3]
+	progress := styles.RenderProgressBar(20, g.Usage)
+	usageStr := fmt.Sprintf("%s %.0f%%", progress, g.Usage)
 
-	// Overwrite with sensors data if available
-	outSensors, errSensors := exec.Command("sensors").Output()
-	if errSensors == nil {
-		re := regexp.MustCompile(`gpu_fan:\s+(\d+\s+RPM)`)
-		matches := re.FindStringSubmatch(string(outSensors))
-		if len(matches) > 1 {
-			gpuFans = matches[1]
-		}
+	fanStr := g.Fans
+	if !strings.Contains(fanStr, "RPM") && !strings.Contains(fanStr, "N/A") {
+		fanStr += "%"
 	}
 
 	// Calculate Percentages
-	var memUsedPercent, memFreePercent string = "0%", "0%"
-	totalVal, _ := strconv.ParseFloat(fields[4], 64)
-	usedVal, _ := strconv.ParseFloat(fields[5], 64)
-	freeVal, _ := strconv.ParseFloat(fields[6], 64)
-
-	if totalVal > 0 {
-		memUsedPercent = fmt.Sprintf("%.0f%%", (usedVal/totalVal)*100)
-		memFreePercent = fmt.Sprintf("%.0f%%", (freeVal/totalVal)*100)
+	usedPercent, freePercent := "0%", "0%"
+	if g.MemoryTotal > 0 {
+		usedPercent = fmt.Sprintf("%.0f%%", (g.MemoryUsed/g.MemoryTotal)*100)
+		freePercent = fmt.Sprintf("%.0f%%", (g.MemoryFree/g.MemoryTotal)*100)
 	}
 
-	return GpuStatsMsg{
-		id:                   id,
-		gpuName:              fields[0],
-		gpuUsage:             fields[1],
-		gpuTemp:              fields[2],
-		gpuFans:              gpuFans,
-		gpuMemoryTotal:       fields[4],
-		gpuMemoryUsed:        fields[5],
-		gpuMemoryFree:        fields[6],
-		gpuMemoryUsedPercent: memUsedPercent,
-		gpuMemoryFreePercent: memFreePercent,
+	id := styles.RenderStat("  UUID:", g.UUID)
+	if g.UUID == "" {
+		id = styles.RenderStat("  PCI Bus:", g.BusID+" ("+g.Driver+")")
+	}
+	lines := []string{
+		styles.RenderStat("󰍛 Model:", g.Name),
+		id,
+		"",
+		styles.RenderStat("󰾆 Usage:", usageStr),
+		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render(" Temp:"), tempStr),
+		styles.RenderStat("󰜮 Fans:", fanStr),
+	}
+	if g.PowerDraw > 0 {
+		power := fmt.Sprintf("%.1f W", g.PowerDraw)
+		if g.PowerLimit > 0 {
+			power = fmt.Sprintf("%s %.1f / %.0f W", styles.RenderProgressBar(20, g.PowerDraw/g.PowerLimit*100), g.PowerDraw, g.PowerLimit)
+		}
+		lines = append(lines, styles.RenderStat("  Power:", power))
+	}
+	if g.Driver == "nvidia" {
+		lines = append(lines, renderNvidiaStats(g)...)
 	}
+	lines = append(lines, "")
+	if g.MemoryTotal == 0 {
+		// Integrated GPUs have no memory of their own
+		return lipgloss.JoinVertical(lipgloss.Left, append(lines, styles.RenderStat(" Memory:", "Shared with system"))...)
+	}
+	lines = append(lines,
+		styles.RenderStat(" Memory Total:", fmt.Sprintf("%.0f MiB", g.MemoryTotal)),
+		styles.RenderStat(fmt.Sprintf(" Memory Used (%s):", usedPercent), fmt.Sprintf("%.0f MiB", g.MemoryUsed)),
+		styles.RenderStat(fmt.Sprintf(" Memory Free (%s):", freePercent), fmt.Sprintf("%.0f MiB", g.MemoryFree)),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
-func getGpuStats(id int) tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return collectNvidiaData(id)
-	})
+// renderNvidiaStats renders the figures only nvidia-smi reports.
+func renderNvidiaStats(g collector.GpuDevice) []string {
+	var lines []string
+	if g.ClockSM > 0 {
+		lines = append(lines, styles.RenderStat("  Clocks SM / Memory:", fmt.Sprintf("%.0f / %.0f MHz", g.ClockSM, g.ClockMemory)))
+	}
+	if g.PcieGen > 0 {
+		lines = append(lines, styles.RenderStat("  PCIe Link:", fmt.Sprintf("Gen%d x%d", g.PcieGen, g.PcieWidth)))
+	}
+	lines = append(lines, styles.RenderStat("  Encoder / Decoder:", fmt.Sprintf("%.0f%% / %.0f%%", g.EncoderUsage, g.DecoderUsage)))
+
+	throttle, color := "None", styles.ColorText
+	if len(g.ThrottleReasons) > 0 {
+		throttle = strings.Join(g.ThrottleReasons, ", ")
+	}
+	for _, r := range g.ThrottleReasons {
+		// Idling and clocks pinned on purpose are not worth a warning
+		if r != "Idle" && r != "App clocks" && r != "Display clocks" {
+			color = styles.ColorWarning
+		}
+	}
+	lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render("  Throttle:"), styles.StatValueStyle.Foreground(color).Render(throttle)))
+
+	if len(g.Processes) > 0 {
+		var used float64
+		for _, p := range g.Processes {
+			used += p.UsedMemory
+		}
+		lines = append(lines, styles.RenderStat("  Processes:", fmt.Sprintf("%d using %.0f MiB", len(g.Processes), used)))
+	}
+	return lines
 }
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+
+	"go-test/src/collector"
+	"go-test/src/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colours of the stacked RAM bar and its legend.
+var (
+	memUsedColor    = styles.ColorSecondary
+	memCacheColor   = styles.ColorCyan
+	memBuffersColor = styles.ColorWarning
+	memFreeColor    = styles.ColorSubtext
+)
+
+type MemoryModel struct {
+	Memory collector.MemorySample
+	Err    error
+	Loaded bool
+}
+
+func NewMemoryModel() MemoryModel {
+	return MemoryModel{}
+}
+
+func (m MemoryModel) Update(msg tea.Msg) (MemoryModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SampleMsg:
+		if msg.Name != collector.Memory {
+			return m, nil
+		}
+		m.Loaded = true
+		m.Err = msg.Err
+		m.Memory, _ = msg.Sample.(collector.MemorySample)
+	}
+	return m, nil
+}
+
+func (m MemoryModel) View() string {
+	title := styles.TitleStyle.Render("MEMORY DETAILS")
+
+	var content string
+	switch {
+	case !m.Loaded:
+		content = styles.StatKeyStyle.Render("Loading...")
+	case m.Err != nil || m.Memory.Total == 0:
+		content = styles.RenderStat(" RAM:", "N/A")
+	default:
+		content = lipgloss.JoinVertical(lipgloss.Left,
+			m.renderRam(),
+			"",
+			m.renderSwap(),
+		)
+	}
+
+	box := styles.StatBoxStyle.Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, box)
+}
+
+func (m MemoryModel) renderRam() string {
+	mem := m.Memory
+	free := mem.Total - min(mem.Total, mem.Used+mem.Cached+mem.Buffers)
+	total := float64(mem.Total)
+
+	bar := styles.RenderStackedBar(60, total,
+		styles.BarSegment{Value: float64(mem.Used), Color: memUsedColor},
+		styles.BarSegment{Value: float64(mem.Cached), Color: memCacheColor},
+		styles.BarSegment{Value: float64(mem.Buffers), Color: memBuffersColor},
+		styles.BarSegment{Value: float64(free), Color: memFreeColor},
+	)
+	legend := func(color lipgloss.Color, label string, bytes uint64) string {
+		return lipgloss.NewStyle().Foreground(color).Render("■") + " " +
+			styles.StatValueStyle.Render(fmt.Sprintf("%s %s (%.0f%%)", label, formatSize(bytes), float64(bytes)/total*100))
+	}
+
+	lines := []string{
+		bar,
+		legend(memUsedColor, "Used", mem.Used) + "  " + legend(memCacheColor, "Cache", mem.Cached),
+		legend(memBuffersColor, "Buffers", mem.Buffers) + "  " + legend(memFreeColor, "Free", free),
+		"",
+		styles.RenderStat(" RAM Total / Available:", fmt.Sprintf("%s / %s", formatSize(mem.Total), formatSize(mem.Available))),
+		styles.RenderStat(" Shared:", formatSize(mem.Shared)),
+		styles.RenderStat(" Dirty / Writeback:", fmt.Sprintf("%s / %s", formatSize(mem.Dirty), formatSize(mem.Writeback))),
+	}
+	if hp := mem.HugePages; hp.Total > 0 {
+		lines = append(lines, styles.RenderStat(" Huge Pages:", fmt.Sprintf("%d of %d free, %d reserved (%s each)", hp.Free, hp.Total, hp.Reserved, formatSize(hp.PageSize))))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m MemoryModel) renderSwap() string {
+	mem := m.Memory
+	if mem.SwapTotal == 0 && len(mem.Zram) == 0 && mem.Zswap == nil {
+		return styles.RenderStat("󰓡 Swap:", "None")
+	}
+
+	var lines []string
+	if mem.SwapTotal > 0 {
+		usedP := float64(mem.SwapUsed) / float64(mem.SwapTotal) * 100
+		lines = append(lines,
+			styles.RenderStat("󰓡 Swap:", fmt.Sprintf("%s %s / %s", styles.RenderProgressBar(20, usedP), formatSize(mem.SwapUsed), formatSize(mem.SwapTotal))),
+			styles.RenderStat("  Swap Cached:", formatSize(mem.SwapCached)),
+			styles.RenderStat("  Swap In / Out:", fmt.Sprintf("%s / %s", formatSpeed(mem.SwapInPerSec), formatSpeed(mem.SwapOutPerSec))),
+		)
+	} else {
+		lines = append(lines, styles.RenderStat("󰓡 Swap:", "None"))
+	}
+
+	for _, z := range mem.Zram {
+		ratio := "-"
+		if z.Compressed > 0 {
+			ratio = fmt.Sprintf("%.1fx", float64(z.OrigData)/float64(z.Compressed))
+		}
+		lines = append(lines, styles.RenderStat("  "+z.Name+" ("+z.Algorithm+"):",
+			fmt.Sprintf("%s stored in %s, ratio %s", formatSize(z.OrigData), formatSize(z.MemUsed), ratio)))
+	}
+	if z := mem.Zswap; z != nil {
+		state := "disabled"
+		if z.Enabled {
+			state = fmt.Sprintf("%s stored in %s", formatSize(z.Stored), formatSize(z.PoolSize))
+		}
+		lines = append(lines, styles.RenderStat("  zswap:", state))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"go-test/src/collector"
 	"go-test/src/styles"
 	"time"
 
@@ -19,20 +20,37 @@ type MainModel struct {
 	width  int
 	height int
 
+	updates <-chan collector.Update
+
 	cpuModel     CpuModel
+	memModel     MemoryModel
+	diskModel    DiskModel
+	sensorsModel SensorsModel
 	gpuModel     GpuModel
 	netModel     NetworkModel
+	connModel    ConnectionsModel
 	procModel    ProcessModel
+	infoModel    ProcessInfoModel
 	spinnerIndex int
 	currentTime  time.Time
 }
 
 type HeartbeatMsg time.Time
 
-func InitialModel() MainModel {
+// SampleMsg carries a new collector result from the shared sampler.
+type SampleMsg collector.Update
+
+func InitialModel(sampler *collector.Sampler) MainModel {
+	updates, _ := sampler.Subscribe()
+
+	var detailer collector.ProcessDetailer
+	if c, ok := sampler.Registry().Get(collector.Processes); ok {
+		detailer, _ = c.(collector.ProcessDetailer)
+	}
+
 	return MainModel{
 		// Our to-do list is a grocery list
-		choices: []string{"all", "network", "cpu", "gpu", "processes"},
+		choices: []string{"all", "network", "cpu", "memory", "disks", "sensors", "gpu", "connections", "processes"},
 
 		// A map which indicates which choices are selected. We're using
 		// the map like a mathematical set. The keys refer to the indexes
@@ -40,10 +58,17 @@ func InitialModel() MainModel {
 		selected: make(map[int]struct{}),
 		Page:     "menu", // Start at the menu
 
+		updates: updates,
+
 		cpuModel:     NewCpuModel(),
+		memModel:     NewMemoryModel(),
+		diskModel:    NewDiskModel(),
+		sensorsModel: NewSensorsModel(),
 		gpuModel:     NewGpuModel(),
 		netModel:     NewNetworkModel(),
+		connModel:    NewConnectionsModel(),
 		procModel:    NewProcessModel(),
+		infoModel:    NewProcessInfoModel(detailer),
 		spinnerIndex: 0,
 	}
 }
@@ -54,13 +79,20 @@ func doHeartbeat() tea.Cmd {
 	})
 }
 
+// waitForSample blocks until the sampler publishes the next update.
+func waitForSample(updates <-chan collector.Update) tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return SampleMsg(u)
+	}
+}
+
 func (m MainModel) Init() tea.Cmd {
-	// Trigger a single initial data fetch for all models
 	return tea.Batch(
-		func() tea.Msg { return collectCpuData(m.cpuModel.Id) },
-		func() tea.Msg { return collectNvidiaData(m.gpuModel.Id) },
-		func() tea.Msg { return collectNetworkData(m.netModel.Id, m.netModel.Interface) },
-		func() tea.Msg { return collectProcessData(m.procModel.Id) },
+		waitForSample(m.updates),
 		doHeartbeat(),
 	)
 }
@@ -76,6 +108,22 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinnerIndex++
 		m.currentTime = time.Time(msg)
 		return m, doHeartbeat()
+	case SampleMsg:
+		// Every model stays current in the background, whichever page is shown
+		m.cpuModel, _ = m.cpuModel.Update(msg)
+		m.memModel, _ = m.memModel.Update(msg)
+		m.diskModel, _ = m.diskModel.Update(msg)
+		m.sensorsModel, _ = m.sensorsModel.Update(msg)
+		m.gpuModel, _ = m.gpuModel.Update(msg)
+		m.netModel, _ = m.netModel.Update(msg)
+		m.connModel, _ = m.connModel.Update(msg)
+		m.procModel, _ = m.procModel.Update(msg)
+		if m.Page == "process" {
+			var cmd tea.Cmd
+			m.infoModel, cmd = m.infoModel.Update(msg)
+			return m, tea.Batch(waitForSample(m.updates), cmd)
+		}
+		return m, waitForSample(m.updates)
 	}
 
 	// --- CPU PAGE LOGIC ---
@@ -83,7 +131,6 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Handle return to menu
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
 			m.Page = "menu"
-			m.cpuModel.Polling = false
 			return m, nil
 		}
 
@@ -92,12 +139,50 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	// --- MEMORY PAGE LOGIC ---
+	if m.Page == "memory" {
+		// Handle return to menu
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
+			m.Page = "menu"
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.memModel, cmd = m.memModel.Update(msg)
+		return m, cmd
+	}
+
+	// --- DISKS PAGE LOGIC ---
+	if m.Page == "disks" {
+		// Handle return to menu
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
+			m.Page = "menu"
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.diskModel, cmd = m.diskModel.Update(msg)
+		return m, cmd
+	}
+
+	// --- SENSORS PAGE LOGIC ---
+	if m.Page == "sensors" {
+		// Handle return to menu
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
+			m.Page = "menu"
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.sensorsModel, cmd = m.sensorsModel.Update(msg)
+		return m, cmd
+	}
+
 	// --- GPU PAGE LOGIC ---
 	if m.Page == "gpu" {
 		// Handle return to menu
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
 			m.Page = "menu"
-			m.gpuModel.Polling = false
 			return m, nil
 		}
 
@@ -120,12 +205,34 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
-	// --- PROCESSES PAGE LOGIC ---
-	if m.Page == "processes" {
+	// --- CONNECTIONS PAGE LOGIC ---
+	if m.Page == "connections" {
 		// Handle return to menu
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
 			m.Page = "menu"
-			m.procModel.Polling = false
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.connModel, cmd = m.connModel.Update(msg)
+		return m, cmd
+	}
+
+	// --- PROCESSES PAGE LOGIC ---
+	if m.Page == "processes" {
+		// Handle return to menu, unless the page is taking text input
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " && !m.procModel.Capturing() {
+			m.Page = "menu"
+			return m, nil
+		}
+		// Drill down into the selected process
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "enter" && !m.procModel.Capturing() {
+			if p, ok := m.procModel.Selected(); ok {
+				m.Page = "process"
+				var cmd tea.Cmd
+				m.infoModel, cmd = m.infoModel.Open(p.Pid)
+				return m, cmd
+			}
 			return m, nil
 		}
 
@@ -134,26 +241,42 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	// --- PROCESS DETAIL PAGE LOGIC ---
+	if m.Page == "process" {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case " ":
+				m.Page = "menu"
+				return m, nil
+			case "esc", "backspace":
+				m.Page = "processes"
+				return m, nil
+			}
+		}
+
+		var cmd tea.Cmd
+		m.infoModel, cmd = m.infoModel.Update(msg)
+		return m, cmd
+	}
+
 	// --- ALL PAGE LOGIC ---
 	if m.Page == "all" {
 		// Handle return to menu
 		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == " " {
 			m.Page = "menu"
-			m.cpuModel.Polling = false
-			m.gpuModel.Polling = false
 			m.netModel.Polling = false
-			m.procModel.Polling = false
 			return m, nil
 		}
 
-		// Update all models
-		var cmdC, cmdG, cmdN, cmdP tea.Cmd
+		// Forward to all models
+		var cmdC, cmdM, cmdG, cmdN, cmdP tea.Cmd
 		m.cpuModel, cmdC = m.cpuModel.Update(msg)
+		m.memModel, cmdM = m.memModel.Update(msg)
 		m.gpuModel, cmdG = m.gpuModel.Update(msg)
 		m.netModel, cmdN = m.netModel.Update(msg)
 		m.procModel, cmdP = m.procModel.Update(msg)
 
-		return m, tea.Batch(cmdC, cmdG, cmdN, cmdP)
+		return m, tea.Batch(cmdC, cmdM, cmdG, cmdN, cmdP)
 	}
 
 	// --- MENU PAGE LOGIC ---
@@ -164,47 +287,14 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "enter":
 			m.Page = m.choices[m.cursor]
-			// Start polling logic
-			if m.Page == "cpu" {
-				m.cpuModel.Polling = true
-				m.cpuModel.Id++
-				return m, m.cpuModel.Init()
-			}
-			if m.Page == "gpu" {
-				m.gpuModel.Polling = true
-				m.gpuModel.Id++
-				return m, m.gpuModel.Init()
-			}
-			if m.Page == "network" {
+			// Data comes from the shared sampler; only the speedtest is
+			// tied to the network page being open.
+			if m.Page == "network" || m.Page == "all" {
 				m.netModel.Polling = true
+				m.netModel.IsSpeedtesting = true
 				m.netModel.Id++
 				return m, m.netModel.Init()
 			}
-			if m.Page == "processes" {
-				m.procModel.Polling = true
-				m.procModel.Id++
-				return m, m.procModel.Init()
-			}
-			if m.Page == "all" {
-				m.cpuModel.Polling = true
-				m.cpuModel.Id++
-
-				m.gpuModel.Polling = true
-				m.gpuModel.Id++
-
-				m.netModel.Polling = true
-				m.netModel.Id++
-
-				m.procModel.Polling = true
-				m.procModel.Id++
-
-				return m, tea.Batch(
-					m.cpuModel.Init(),
-					m.gpuModel.Init(),
-					m.netModel.Init(),
-					m.procModel.Init(),
-				)
-			}
 			return m, nil
 		case "up", "k":
 			if m.cursor > 0 {
@@ -235,17 +325,27 @@ func (m MainModel) View() string {
 	switch m.Page {
 	case "cpu":
 		content = m.cpuModel.View()
+	case "memory":
+		content = m.memModel.View()
+	case "disks":
+		content = m.diskModel.View()
+	case "sensors":
+		content = m.sensorsModel.View()
 	case "gpu":
 		content = m.gpuModel.View()
 	case "network":
 		content = m.netModel.View()
+	case "connections":
+		content = m.connModel.View()
 	case "processes":
 		content = m.procModel.View()
+	case "process":
+		content = m.infoModel.View()
 	case "all":
-		// Compose 2x2 grid
-		row1 := lipgloss.JoinHorizontal(lipgloss.Top, m.cpuModel.View(), m.gpuModel.View())
-		row2 := lipgloss.JoinHorizontal(lipgloss.Top, m.netModel.View(), m.procModel.View())
-		content = lipgloss.JoinVertical(lipgloss.Left, row1, row2)
+		// Compose 2x3 grid
+		row1 := lipgloss.JoinHorizontal(lipgloss.Top, m.cpuModel.View(), m.memModel.View())
+		row2 := lipgloss.JoinHorizontal(lipgloss.Top, m.gpuModel.View(), m.netModel.View())
+		content = lipgloss.JoinVertical(lipgloss.Left, row1, row2, m.procModel.View())
 	default:
 		s := styles.MenuTitleStyle.Render("What data would you like to see?") + "\n\n"
 		for i, choice := range m.choices {
@@ -0,0 +1,703 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-test/src/collector"
+	"go-test/src/internal/fakecmd"
+	"go-test/src/speedtest"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestMain(m *testing.M) {
+	fakecmd.Main()
+	os.Exit(m.Run())
+}
+
+var fixtureConfig = collector.Config{
+	ProcRoot: filepath.Join("..", "..", "testdata", "proc"),
+	SysRoot:  filepath.Join("..", "..", "testdata", "sys"),
+}
+
+func collect(t *testing.T, c collector.Collector) SampleMsg {
+	t.Helper()
+	sample, err := c.Collect(context.Background())
+	return SampleMsg{Name: c.Name(), Sample: sample, Err: err}
+}
+
+func TestModelsApplySamples(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   func(t *testing.T) SampleMsg
+		check func(t *testing.T, m MainModel)
+	}{
+		{
+			name: "cpu",
+			msg:  func(t *testing.T) SampleMsg { return collect(t, collector.NewCpuCollector(fixtureConfig)) },
+			check: func(t *testing.T, m MainModel) {
+				if m.cpuModel.CpuName != "Fixture CPU @ 3.00GHz" || m.cpuModel.CpuTemp != 52 {
+					t.Errorf("unexpected cpu model: %+v", m.cpuModel)
+				}
+			},
+		},
+		{
+			name: "load",
+			msg:  func(t *testing.T) SampleMsg { return collect(t, collector.NewLoadCollector(fixtureConfig)) },
+			check: func(t *testing.T, m MainModel) {
+				if m.cpuModel.Load.Load1 != 1.52 || !strings.Contains(m.cpuModel.View(), "2 running, 1 blocked") {
+					t.Errorf("load not shown: %+v", m.cpuModel.Load)
+				}
+			},
+		},
+		{
+			name: "pressure",
+			msg: func(t *testing.T) SampleMsg {
+				return collect(t, collector.NewPressureCollector(fixtureConfig, collector.DefaultPressureThresholds))
+			},
+			check: func(t *testing.T, m MainModel) {
+				view := m.cpuModel.View()
+				for _, want := range []string{"12.5/8.0/3.2", "/user.slice", "io full avg10 > 10%"} {
+					if !strings.Contains(view, want) {
+						t.Errorf("pressure section does not show %q", want)
+					}
+				}
+			},
+		},
+		{
+			name: "memory",
+			msg:  func(t *testing.T) SampleMsg { return collect(t, collector.NewMemoryCollector(fixtureConfig)) },
+			check: func(t *testing.T, m MainModel) {
+				mem := m.memModel.Memory
+				if mem.Total != 8000000*1024 || mem.Used != 3000000*1024 || mem.SwapUsed != 1000000*1024 {
+					t.Errorf("unexpected memory figures: %+v", mem)
+				}
+				view := m.memModel.View()
+				for _, want := range []string{"Used 2.86 GB (38%)", "zram0 (zstd)", "Huge Pages"} {
+					if !strings.Contains(view, want) {
+						t.Errorf("memory page does not show %q", want)
+					}
+				}
+			},
+		},
+		{
+			name: "memory error",
+			msg: func(t *testing.T) SampleMsg {
+				return SampleMsg{Name: collector.Memory, Err: errors.New("no meminfo")}
+			},
+			check: func(t *testing.T, m MainModel) {
+				if m.memModel.Err == nil || !strings.Contains(m.memModel.View(), "N/A") {
+					t.Errorf("memory error not shown")
+				}
+			},
+		},
+		{
+			name: "sensors",
+			msg:  func(t *testing.T) SampleMsg { return collect(t, collector.NewSensorsCollector(fixtureConfig)) },
+			check: func(t *testing.T, m MainModel) {
+				view := m.sensorsModel.View()
+				for _, want := range []string{"coretemp (hwmon0)", "Package id 0 (CPU)", "100.0 °C", "1200 RPM", "1.000 V", "Composite"} {
+					if !strings.Contains(view, want) {
+						t.Errorf("sensors page does not show %q", want)
+					}
+				}
+			},
+		},
+		{
+			name: "gpu",
+			msg:  func(t *testing.T) SampleMsg { return collect(t, collector.NewGpuCollector(fixtureConfig)) },
+			check: func(t *testing.T, m MainModel) {
+				view := m.gpuModel.View()
+				for _, want := range []string{"0: AMD Radeon RX", "1: Intel Graphics", "0000:03:00.0 (amdgpu)", "120.0 W", "16368 MiB"} {
+					if !strings.Contains(view, want) {
+						t.Errorf("gpu page does not show %q", want)
+					}
+				}
+			},
+		},
+		{
+			name: "gpu parse error",
+			msg: func(t *testing.T) SampleMsg {
+				return SampleMsg{Name: collector.Gpu, Err: collector.ErrGpuParse}
+			},
+			check: func(t *testing.T, m MainModel) {
+				if !strings.Contains(m.gpuModel.View(), "Error parsing") {
+					t.Errorf("gpu parse error not shown")
+				}
+			},
+		},
+		{
+			name: "processes",
+			msg:  func(t *testing.T) SampleMsg { return collect(t, collector.NewProcessCollector(fixtureConfig)) },
+			check: func(t *testing.T, m MainModel) {
+				if rows := m.procModel.Rows(); len(rows) != 6 {
+					t.Errorf("got %d process rows, want 6", len(rows))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := MainModel{
+				cpuModel:  NewCpuModel(),
+				memModel:  NewMemoryModel(),
+				gpuModel:  NewGpuModel(),
+				netModel:  NewNetworkModel(),
+				procModel: NewProcessModel(),
+			}
+			updated, _ := m.Update(tt.msg(t))
+			tt.check(t, updated.(MainModel))
+		})
+	}
+}
+
+func TestProcessTableKeys(t *testing.T) {
+	procs := []collector.ProcessStat{
+		{Pid: 1, Name: "systemd", User: "root", CpuPercent: 0.5, RSS: 300},
+		{Pid: 20, Name: "bash", User: "alice", CpuPercent: 2, RSS: 100},
+		{Pid: 300, Name: "cc1plus", User: "build", CpuPercent: 90, RSS: 200, Cmdline: "cc1plus -O2 main.cpp"},
+	}
+
+	keys := func(s ...string) []tea.KeyMsg {
+		msgs := make([]tea.KeyMsg, len(s))
+		for i, k := range s {
+			switch k {
+			case "enter":
+				msgs[i] = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msgs[i] = tea.KeyMsg{Type: tea.KeyEsc}
+			default:
+				msgs[i] = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			}
+		}
+		return msgs
+	}
+
+	tests := []struct {
+		name     string
+		keys     []tea.KeyMsg
+		pids     []int
+		selected int
+	}{
+		{"default sorts by cpu", nil, []int{300, 20, 1}, 300},
+		{"move down", keys("j"), []int{300, 20, 1}, 20},
+		{"cursor stops at the end", keys("j", "j", "j", "j"), []int{300, 20, 1}, 1},
+		{"sort by pid", keys("1"), []int{1, 20, 300}, 1},
+		{"sort by pid descending", keys("1", "1"), []int{300, 20, 1}, 300},
+		{"sort by user", keys("2"), []int{20, 300, 1}, 20},
+		{"sort by rss", keys("6"), []int{1, 300, 20}, 1},
+		{"filter on command line", keys("/", "m", "a", "i", "n", "enter"), []int{300}, 300},
+		{"filter on user", keys("/", "a", "l", "i", "c", "e"), []int{20}, 20},
+		{"clear filter", keys("/", "x", "esc"), []int{300, 20, 1}, 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewProcessModel()
+			m, _ = m.Update(SampleMsg{Name: collector.Processes, Sample: collector.ProcessSample{Processes: procs}})
+			for _, k := range tt.keys {
+				m, _ = m.Update(k)
+			}
+
+			var pids []int
+			for _, p := range m.Rows() {
+				pids = append(pids, p.Pid)
+			}
+			if fmt.Sprint(pids) != fmt.Sprint(tt.pids) {
+				t.Errorf("rows = %v, want %v", pids, tt.pids)
+			}
+			if p, _ := m.Selected(); p.Pid != tt.selected {
+				t.Errorf("selected pid = %d, want %d", p.Pid, tt.selected)
+			}
+		})
+	}
+}
+
+func TestProcessActionConfirmation(t *testing.T) {
+	m := NewProcessModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Processes, Sample: collector.ProcessSample{Processes: []collector.ProcessStat{{Pid: 99999999, Name: "ghost"}}}})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	if !m.Capturing() || m.pending == nil || m.pending.signal != "KILL" {
+		t.Fatalf("K did not ask for confirmation: %+v", m.pending)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if cmd != nil || m.Capturing() {
+		t.Fatalf("declining still ran the action")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatalf("confirming did not run the action")
+	}
+
+	// The pid does not exist, so the error must be surfaced
+	m, _ = m.Update(cmd())
+	if m.lastErr == nil || !strings.Contains(m.View(), m.lastErr.Error()) {
+		t.Errorf("error not shown inline, lastErr = %v", m.lastErr)
+	}
+}
+
+func TestProcessTree(t *testing.T) {
+	procs := []collector.ProcessStat{
+		{Pid: 1, PPid: 0, Name: "init", CpuPercent: 1, RSS: 10},
+		{Pid: 10, PPid: 1, Name: "make", CpuPercent: 0, RSS: 20},
+		{Pid: 11, PPid: 10, Name: "cc1", CpuPercent: 80, RSS: 300},
+		{Pid: 12, PPid: 10, Name: "cc1", CpuPercent: 70, RSS: 200},
+		{Pid: 20, PPid: 1, Name: "sshd", CpuPercent: 5, RSS: 40},
+	}
+
+	m := NewProcessModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Processes, Sample: collector.ProcessSample{Processes: procs}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	rows := m.Rows()
+	var got []string
+	for _, r := range rows {
+		got = append(got, fmt.Sprintf("%s%d", r.prefix, r.Pid))
+	}
+	want := []string{"1", "├─ 10", "│  ├─ 11", "│  └─ 12", "└─ 20"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("tree = %q, want %q", got, want)
+	}
+	if rows[1].treeCpu != 150 || rows[1].treeRss != 520 {
+		t.Errorf("make subtree totals = %v%% %d, want 150%% 520", rows[1].treeCpu, rows[1].treeRss)
+	}
+	if rows[0].treeCpu != 156 {
+		t.Errorf("init subtree cpu = %v, want 156", rows[0].treeCpu)
+	}
+
+	// Collapse the make branch
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if rows := m.Rows(); len(rows) != 3 || !rows[1].collapsed || rows[1].children != 2 {
+		t.Errorf("collapsing did not hide the children: %+v", rows)
+	}
+}
+
+func TestProcessDrillDown(t *testing.T) {
+	procs := collector.NewProcessCollector(fixtureConfig)
+	m := MainModel{
+		Page:      "processes",
+		procModel: NewProcessModel(),
+		infoModel: NewProcessInfoModel(procs),
+	}
+	next, _ := m.Update(collect(t, procs))
+	m = next.(MainModel)
+
+	for _, k := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("/")},
+		{Type: tea.KeyRunes, Runes: []rune("web")},
+		{Type: tea.KeyEnter},
+	} {
+		next, _ = m.Update(k)
+		m = next.(MainModel)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(MainModel)
+	if m.Page != "process" || m.infoModel.Pid != 100 || cmd == nil {
+		t.Fatalf("enter did not open the detail page: page %q pid %d", m.Page, m.infoModel.Pid)
+	}
+
+	next, _ = m.Update(cmd())
+	m = next.(MainModel)
+	view := m.View()
+	for _, want := range []string{"/home/user/projects", "DB_PASSWORD=********", "session-2.scope"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail page does not show %q", want)
+		}
+	}
+	if strings.Contains(view, "hunter2") {
+		t.Errorf("detail page leaks a redacted value")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if page := next.(MainModel).Page; page != "processes" {
+		t.Errorf("esc went to %q, want processes", page)
+	}
+}
+
+func TestDiskPseudoToggle(t *testing.T) {
+	sample := collector.DiskSample{
+		Filesystems: []collector.FilesystemStat{
+			{MountPoint: "/", FSType: "ext4", Size: 100, Used: 40, Free: 60},
+			{MountPoint: "/proc", FSType: "proc", Pseudo: true},
+			{MountPoint: "/run", FSType: "tmpfs", Size: 10, Pseudo: true},
+			{MountPoint: "/mnt/nas", FSType: "nfs4", Err: "statfs timed out"},
+		},
+		Devices: []collector.BlockDeviceStat{{Name: "sda"}, {Name: "loop0", Pseudo: true}},
+	}
+
+	m := NewDiskModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Disks, Sample: sample})
+	if got := len(m.Filesystems()); got != 2 {
+		t.Errorf("got %d filesystems by default, want 2", got)
+	}
+	if view := m.View(); !strings.Contains(view, "/mnt/nas      nfs4   Unavailable: statfs timed out") {
+		t.Errorf("unavailable mount not shown:\n%s", view)
+	}
+	if got := len(m.Devices()); got != 1 {
+		t.Errorf("got %d devices by default, want 1", got)
+	}
+	if view := m.View(); !strings.Contains(view, "2 hidden") || strings.Contains(view, "/proc") {
+		t.Errorf("pseudo filesystems not hidden:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got := len(m.Filesystems()); got != 4 {
+		t.Errorf("got %d filesystems after toggling, want 4", got)
+	}
+	if !strings.Contains(m.View(), "loop0") {
+		t.Errorf("virtual devices not shown after toggling")
+	}
+}
+
+func TestFormatFullIn(t *testing.T) {
+	hours := func(h float64) collector.FilesystemStat {
+		s := h * 3600
+		return collector.FilesystemStat{FullInSeconds: &s}
+	}
+	tests := []struct {
+		fs   collector.FilesystemStat
+		want string
+	}{
+		{collector.FilesystemStat{}, "-"},
+		{hours(0.5), "30m"},
+		{hours(5.5), "5h"},
+		{hours(72), "3d"},
+		{hours(24 * 400), ">1y"},
+	}
+	for _, tt := range tests {
+		if got := formatFullIn(tt.fs); got != tt.want {
+			t.Errorf("formatFullIn(%v) = %q, want %q", tt.fs.FullInSeconds, got, tt.want)
+		}
+	}
+}
+
+func TestGpuSelector(t *testing.T) {
+	m := NewGpuModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Gpu, Sample: collector.GpuSample{Gpus: []collector.GpuDevice{
+		{Index: 0, Card: "nvidia0", UUID: "GPU-aaaa", Name: "NVIDIA GeForce RTX 4090", Temp: 71},
+		{Index: 0, Card: "card0", BusID: "0000:00:02.0", Name: "Intel Graphics (8086:a780)", Driver: "i915", Temp: 38},
+	}}})
+	if view := m.View(); !strings.Contains(view, "GPU-aaaa") || !strings.Contains(view, "nvidia0: GeForce RTX 4090") || !strings.Contains(view, "card0: Intel Graphics") {
+		t.Errorf("first card or selector not shown:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if m.Selected != 1 || !strings.Contains(m.View(), "0000:00:02.0") {
+		t.Errorf("] did not select the second GPU")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if m.Selected != 0 {
+		t.Errorf("] did not wrap around, Selected = %d", m.Selected)
+	}
+
+	// A GPU disappearing keeps the selection in range
+	m.Selected = 1
+	m, _ = m.Update(SampleMsg{Name: collector.Gpu, Sample: collector.GpuSample{Gpus: []collector.GpuDevice{{Name: "NVIDIA GeForce RTX 4090"}}}})
+	if m.Selected != 0 {
+		t.Errorf("Selected = %d after a GPU went away, want 0", m.Selected)
+	}
+}
+
+func TestProcessGpuMemory(t *testing.T) {
+	m := NewProcessModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Processes, Sample: collector.ProcessSample{Processes: []collector.ProcessStat{
+		{Pid: 1, Name: "systemd", CpuPercent: 0.5},
+		{Pid: 20, Name: "python3", CpuPercent: 2},
+		{Pid: 300, Name: "cc1plus", CpuPercent: 90},
+	}}})
+	m, _ = m.Update(SampleMsg{Name: collector.Gpu, Sample: collector.GpuSample{Gpus: []collector.GpuDevice{
+		{Index: 0, Processes: []collector.GpuProcess{{Pid: 20, UsedMemory: 10}, {Pid: 1, UsedMemory: 2}}},
+		{Index: 1, Processes: []collector.GpuProcess{{Pid: 1, UsedMemory: 3}}},
+	}}})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7")})
+	var pids []int
+	for _, p := range m.Rows() {
+		pids = append(pids, p.Pid)
+	}
+	if fmt.Sprint(pids) != "[20 1 300]" {
+		t.Errorf("rows sorted by GPU memory = %v, want [20 1 300]", pids)
+	}
+	if got := m.Rows()[1].gpuMemory; got != 5<<20 {
+		t.Errorf("pid 1 GPU memory = %d, want the total over both GPUs", got)
+	}
+	if view := m.View(); !strings.Contains(view, "GPU▼") {
+		t.Errorf("GPU column not shown as the sort column")
+	}
+}
+
+func TestSpeedtest(t *testing.T) {
+	// A static mirror, which refuses uploads
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			http.Error(w, "read only", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write(make([]byte, 256<<10))
+	}))
+	defer mirror.Close()
+	uploads := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer uploads.Close()
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	tests := []struct {
+		name      string
+		url       string
+		uploadURL string
+		err       string
+		view      string
+	}{
+		{"result", mirror.URL, uploads.URL, "", "↑ "},
+		{"download only", mirror.URL, "", "", "jitter"},
+		{"upload refused", mirror.URL, mirror.URL, "", "Failed (POST"},
+		{"not found", notFound.URL, "", "404 Not Found", "Failed"},
+		{"not configured", "", "", "no speedtest URL", "Not configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := speedtest.Config{URL: tt.url, UploadURL: tt.uploadURL, Streams: 2, Duration: 100 * time.Millisecond, Pings: 2}
+			msg := runSpeedtest(7, cfg)().(SpeedtestMsg)
+			if msg.Id != 7 {
+				t.Errorf("msg.Id = %d, want 7", msg.Id)
+			}
+			if tt.err == "" && (msg.Err != nil || msg.Result.Download <= 0) {
+				t.Errorf("msg = %+v, want a download", msg)
+			}
+			if uploaded := msg.Result.Upload > 0; uploaded != (tt.uploadURL == uploads.URL) {
+				t.Errorf("Upload = %v (%v)", msg.Result.Upload, msg.Result.UploadErr)
+			}
+			if tt.err != "" && (msg.Err == nil || !strings.Contains(msg.Err.Error(), tt.err)) {
+				t.Errorf("Err = %v, want %q", msg.Err, tt.err)
+			}
+
+			m := NewNetworkModel()
+			m.Id = 7
+			m, cmd := m.Update(msg)
+			if view := m.View(); !strings.Contains(view, tt.view) {
+				t.Errorf("view does not show %q:\n%s", tt.view, view)
+			}
+			// Without a server there is nothing to retry
+			if rescheduled := cmd != nil; rescheduled != (tt.url != "") {
+				t.Errorf("rescheduled = %v", rescheduled)
+			}
+		})
+	}
+}
+
+func TestGpuPageNvidiaSmi(t *testing.T) {
+	// No DRM cards, so nvidia-smi is the only source
+	cfg := collector.Config{ProcRoot: fixtureConfig.ProcRoot, SysRoot: t.TempDir()}
+
+	tests := []struct {
+		name      string
+		responses []fakecmd.Response // nil leaves nvidia-smi missing
+		want      []string
+	}{
+		{
+			name: "multi gpu",
+			responses: []fakecmd.Response{
+				{Args: "--query-gpu=", Stdout: fakecmd.ReadFile(t, "nvidia-smi", "query-gpu.csv")},
+				{Args: "--query-compute-apps=", Stdout: fakecmd.ReadFile(t, "nvidia-smi", "compute-apps.csv")},
+			},
+			want: []string{"0: GeForce RTX 4090", "1: RTX A4000", "SW power cap", "2 using 19456 MiB"},
+		},
+		{name: "missing", want: []string{"N/A"}},
+		{
+			name:      "driver not loaded",
+			responses: []fakecmd.Response{{Stdout: fakecmd.ReadFile(t, "nvidia-smi", "driver-error.txt"), ExitCode: 9}},
+			want:      []string{"N/A"},
+		},
+		{
+			name:      "malformed",
+			responses: []fakecmd.Response{{Stdout: fakecmd.ReadFile(t, "nvidia-smi", "malformed.txt")}},
+			want:      []string{"Error parsing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.responses == nil {
+				fakecmd.EmptyPath(t)
+			} else {
+				fakecmd.Install(t, "nvidia-smi", tt.responses...)
+			}
+			m, _ := NewGpuModel().Update(collect(t, collector.NewGpuCollector(cfg)))
+			view := m.View()
+			for _, want := range tt.want {
+				if !strings.Contains(view, want) {
+					t.Errorf("gpu page does not show %q:\n%s", want, view)
+				}
+			}
+		})
+	}
+}
+
+func TestNetworkInterfaces(t *testing.T) {
+	m := NewNetworkModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Network, Sample: collector.NetworkSample{
+		Default: collector.InterfaceInfo{
+			Name: "wlan0", NetType: "WiFi", WifiBand: "5GHz",
+			IPv4: collector.Route{Interface: "wlan0", Gateway: "192.168.1.1"},
+			IPv6: collector.Route{Interface: "wlan0", Gateway: "fe80::1"},
+		},
+		Interfaces: []collector.InterfaceStat{
+			{Name: "lo", State: "unknown", Virtual: true},
+			{Name: "eth0", State: "down", MAC: "52:54:00:12:34:56", Addrs: []string{"192.168.1.20/24"}},
+			{Name: "wlan0", State: "up", WiFi: true, RecvBytesPerSec: 2048, DropsIn: 17},
+			{Name: "docker0", State: "down", Virtual: true},
+		},
+	}})
+
+	if c, _ := m.SelectedInterface(); c.Name != "wlan0" || m.DownloadRate != 2048 {
+		t.Errorf("selected %q at %v B/s, want the default route interface", c.Name, m.DownloadRate)
+	}
+	view := m.View()
+	for _, want := range []string{"wlan0 (default route)", "WiFi (5GHz)", "2 virtual hidden", "0/17", "192.168.1.1, fe80::1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("network page does not show %q", want)
+		}
+	}
+	if strings.Contains(view, "docker0") {
+		t.Errorf("virtual interfaces shown while hidden")
+	}
+
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	m, _ = m.Update(tab)
+	if c, _ := m.SelectedInterface(); c.Name != "eth0" || !strings.Contains(m.View(), "192.168.1.20/24") {
+		t.Errorf("tab selected %q, want eth0 with its address", c.Name)
+	}
+
+	// Virtual interfaces are grouped after the hardware ones
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	var names []string
+	for _, c := range m.VisibleInterfaces() {
+		names = append(names, c.Name)
+	}
+	if fmt.Sprint(names) != "[eth0 wlan0 lo docker0]" {
+		t.Errorf("visible interfaces = %v", names)
+	}
+	m, _ = m.Update(tab)
+	m, _ = m.Update(tab)
+	if c, _ := m.SelectedInterface(); c.Name != "lo" {
+		t.Errorf("selected %q, want lo", c.Name)
+	}
+
+	// Hiding virtual interfaces again falls back to the default route
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if c, _ := m.SelectedInterface(); c.Name != "wlan0" {
+		t.Errorf("selected %q after hiding virtual interfaces, want wlan0", c.Name)
+	}
+}
+
+func TestNetworkWireless(t *testing.T) {
+	m := NewNetworkModel()
+	m, _ = m.Update(SampleMsg{Name: collector.Network, Sample: collector.NetworkSample{
+		Default:    collector.InterfaceInfo{Name: "wlan0", NetType: "WiFi"},
+		Interfaces: []collector.InterfaceStat{{Name: "wlan0", State: "up", WiFi: true}},
+	}})
+	link := collector.WirelessLink{
+		Interface: "wlan0", Connected: true,
+		SSID: "Home Net", BSSID: "3c:22:fb:8a:11:08",
+		Frequency: 5955, Channel: 1, Band: "6GHz",
+		Quality: 77, RxBitrate: 866.7, TxBitrate: 780,
+	}
+	for _, signal := range []float64{-70, -60, -50} {
+		link.Signal = signal
+		m, _ = m.Update(SampleMsg{Name: collector.Wireless, Sample: collector.WirelessSample{Links: []collector.WirelessLink{link}}})
+	}
+
+	if got := m.SignalHistory["wlan0"]; fmt.Sprint(got) != "[-70 -60 -50]" {
+		t.Errorf("signal history = %v", got)
+	}
+	view := m.View()
+	for _, want := range []string{"WiFi (6GHz)", "Home Net (3c:22:fb:8a:11:08)", "5955 MHz • Channel 1", "-50 dBm (77%)", "866.7 / 780.0 Mbit/s"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("network page does not show %q", want)
+		}
+	}
+
+	// Without nl80211 only the signal is known, and the page says why
+	m, _ = m.Update(SampleMsg{Name: collector.Wireless, Sample: collector.WirelessSample{Links: []collector.WirelessLink{{
+		Interface: "wlan0", Connected: true, Signal: -50, Quality: 85, Err: "nl80211: not available, is cfg80211 loaded?",
+	}}}})
+	view = m.View()
+	for _, want := range []string{"SSID:                         Unknown", "nl80211: not available"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("network page does not show %q", want)
+		}
+	}
+
+	m, _ = m.Update(SampleMsg{Name: collector.Wireless, Sample: collector.WirelessSample{Links: []collector.WirelessLink{{Interface: "wlan0"}}}})
+	if !strings.Contains(m.View(), "Not connected") || len(m.SignalHistory["wlan0"]) != 0 {
+		t.Errorf("disconnected link still shown, history %v", m.SignalHistory["wlan0"])
+	}
+}
+
+func TestConnectionsPage(t *testing.T) {
+	m := NewConnectionsModel()
+	m, _ = m.Update(collect(t, collector.NewConnectionsCollector(fixtureConfig)))
+
+	view := m.View()
+	for _, want := range []string{"LISTEN 3 • ESTABLISHED 2 • TIME_WAIT 1", "[fd00::99]:50122", "100/Web Content", "1-9/9"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("connections page does not show %q", want)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if rows := m.Rows(); len(rows) != 5 {
+		t.Errorf("got %d listening sockets, want 5", len(rows))
+	}
+	if view := m.View(); strings.Contains(view, "140.82.112.3") || !strings.Contains(view, "300/sshd") {
+		t.Errorf("listening view shows the wrong sockets:\n%s", view)
+	}
+
+	// Scrolling stops at the last page
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	if m.offset != 0 {
+		t.Errorf("scrolled to %d with a single page", m.offset)
+	}
+}
+
+func TestNetworkProcesses(t *testing.T) {
+	m := NewNetworkModel()
+	var procs []collector.ProcessNetStat
+	for pid := 100; pid < 107; pid++ {
+		procs = append(procs, collector.ProcessNetStat{Pid: pid, Name: fmt.Sprintf("proc%d", pid), Processes: 1, Connections: 2, RecvBytesPerSec: 2048})
+	}
+	procs[1] = collector.ProcessNetStat{Pid: 101, Name: "nginx", Namespace: "net:[4026532451]", Processes: 3, SentBytesPerSec: 1 << 20}
+	m, _ = m.Update(SampleMsg{Name: collector.NetworkProcesses, Sample: collector.NetworkProcessesSample{
+		Processes: procs,
+		SocketErr: "sock_diag: permission denied",
+	}})
+
+	view := m.View()
+	for _, want := range []string{"proc100", "nginx", "1.00 MB/s", "own (3 procs)", "proc104", "Per-socket counters unavailable: sock_diag: permission denied"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("network page does not show %q", want)
+		}
+	}
+	if strings.Contains(view, "proc105") {
+		t.Errorf("network page lists more than %d processes", topProcesses)
+	}
+}
@@ -1,123 +1,77 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 
+	"go-test/src/collector"
+	"go-test/src/speedtest"
 	"go-test/src/styles"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	psnet "github.com/shirou/gopsutil/v3/net"
 )
 
-type NetTickMsg struct {
-	id        int
-	bytesRecv uint64
-	bytesSent uint64
-	timestamp time.Time
-}
-
 type SpeedtestMsg struct {
-	Id       int
-	Download float64
+	Id     int
+	Result speedtest.Result
+	Err    error
 }
 
 type SpeedtestTriggerMsg int
 
+// signalHistory is the number of WiFi signal readings kept per interface.
+const signalHistory = 30
+
+// topProcesses is the number of processes listed by bandwidth.
+const topProcesses = 5
+
 type NetworkModel struct {
 	Id           int
-	Interface    string
+	Interface    string // used for the default route
 	NetType      string // "Wired" or "WiFi"
 	WifiBand     string // "2.4GHz", "5GHz" or ""
 	Ipv6Disabled bool
+	IPv4Route    collector.Route
+	IPv6Route    collector.Route
+
+	Interfaces  []collector.InterfaceStat
+	Selected    string // interface shown in detail, the default one if empty
+	ShowVirtual bool   // list loopback, bridges, veth pairs and the like
 
-	DownloadRate float64 // Bytes per second
-	UploadRate   float64 // Bytes per second
+	Processes collector.NetworkProcessesSample // by bandwidth, busiest first
 
-	lastBytesRecv uint64
-	lastBytesSent uint64
-	lastCheck     time.Time
+	Wireless      collector.WirelessSample
+	SignalHistory map[string][]float64 // dBm, oldest first
+
+	DownloadRate float64 // Bytes per second, of the selected interface
+	UploadRate   float64 // Bytes per second, of the selected interface
 
 	Polling bool
 
-	SpeedtestDownload float64
-	SpeedtestTime     string
-	IsSpeedtesting    bool
+	Speedtest       speedtest.Config // server tested against
+	SpeedtestResult speedtest.Result
+	SpeedtestErr    error
+	SpeedtestTime   string
+	IsSpeedtesting  bool
 }
 
 func NewNetworkModel() NetworkModel {
-	m := NetworkModel{
+	return NetworkModel{
 		Id:        0,
 		Interface: "Detecting...",
 		NetType:   "Unknown",
-	}
-	m.detectInterfaceInfo()
-	return m
-}
-
-func (m *NetworkModel) detectInterfaceInfo() {
-	// 1. Find default interface using `ip route`
-	// ip route get 1.1.1.1 | grep -oP 'dev \K\S+'
-	out, err := exec.Command("sh", "-c", "ip route get 1.1.1.1 | grep -oP 'dev \\K\\S+'").Output()
-	if err == nil {
-		m.Interface = strings.TrimSpace(string(out))
-	} else {
-		m.Interface = "eth0" // Fallback
-	}
-
-	// 2. Check if wireless
-	// Check if /sys/class/net/<iface>/wireless exists
-	_, err = os.ReadDir("/sys/class/net/" + m.Interface + "/wireless")
-	if err == nil {
-		m.NetType = "WiFi"
-		// Try to find frequency using iw
-		// iw dev <iface> link
-		iwOut, _ := exec.Command("iw", "dev", m.Interface, "link").Output()
-		iwStr := string(iwOut)
-		if strings.Contains(iwStr, "5.0 MHz") || strings.Contains(iwStr, "5180") || strings.Contains(iwStr, "freq: 5") {
-			m.WifiBand = "5GHz"
-		} else if strings.Contains(iwStr, "freq: 2") {
-			m.WifiBand = "2.4GHz"
-		} else {
-			m.WifiBand = "" // Unknown band
-		}
-	} else {
-		m.NetType = "Wired"
-	}
-
-	// 3. Check IPv6
-	// cat /proc/sys/net/ipv6/conf/all/disable_ipv6
-	ipv6Out, err := os.ReadFile("/proc/sys/net/ipv6/conf/all/disable_ipv6")
-	if err == nil {
-		if strings.TrimSpace(string(ipv6Out)) == "1" {
-			m.Ipv6Disabled = true
-		} else {
-			m.Ipv6Disabled = false
-		}
+		Speedtest: speedtest.DefaultConfig(),
 	}
 }
 
 func (m NetworkModel) Init() tea.Cmd {
 	if m.Polling {
-		// Initialize counters lightly to avoid massive spike on first tick
-		counters, _ := psnet.IOCounters(true)
-		for _, c := range counters {
-			if c.Name == m.Interface {
-				m.lastBytesRecv = c.BytesRecv
-				m.lastBytesSent = c.BytesSent
-			}
-		}
-		m.lastCheck = time.Now()
-		m.IsSpeedtesting = true
-		return tea.Batch(
-			getNetworkTick(m.Id, m.Interface),
-			runSpeedtest(m.Id),
-		)
+		return runSpeedtest(m.Id, m.Speedtest)
 	}
 	return nil
 }
@@ -128,9 +82,13 @@ func (m NetworkModel) Update(msg tea.Msg) (NetworkModel, tea.Cmd) {
 		if msg.Id != m.Id {
 			return m, nil
 		}
-		m.SpeedtestDownload = msg.Download
+		m.SpeedtestResult = msg.Result
+		m.SpeedtestErr = msg.Err
 		m.SpeedtestTime = time.Now().Format("15:04")
 		m.IsSpeedtesting = false
+		if errors.Is(msg.Err, speedtest.ErrNoURL) {
+			return m, nil
+		}
 		// Schedule next one in 5 minutes
 		return m, tea.Tick(5*time.Minute, func(t time.Time) tea.Msg {
 			return SpeedtestTriggerMsg(m.Id)
@@ -143,28 +101,114 @@ func (m NetworkModel) Update(msg tea.Msg) (NetworkModel, tea.Cmd) {
 			return m, nil
 		}
 		m.IsSpeedtesting = true
-		return m, runSpeedtest(m.Id)
-	case NetTickMsg:
-		if msg.id != m.Id {
+		return m, runSpeedtest(m.Id, m.Speedtest)
+	case SampleMsg:
+		if msg.Name == collector.NetworkProcesses && msg.Err == nil {
+			m.Processes, _ = msg.Sample.(collector.NetworkProcessesSample)
+			return m, nil
+		}
+		if msg.Name == collector.Wireless && msg.Err == nil {
+			m.Wireless, _ = msg.Sample.(collector.WirelessSample)
+			m.SignalHistory = updateSignalHistory(m.SignalHistory, m.Wireless)
 			return m, nil
 		}
+		if msg.Name != collector.Network || msg.Err != nil {
+			return m, nil
+		}
+		net, ok := msg.Sample.(collector.NetworkSample)
+		if !ok {
+			return m, nil
+		}
+		m.Interface = net.Default.Name
+		m.NetType = net.Default.NetType
+		m.WifiBand = net.Default.WifiBand
+		m.Ipv6Disabled = net.Default.Ipv6Disabled
+		m.IPv4Route = net.Default.IPv4
+		m.IPv6Route = net.Default.IPv6
+		m.Interfaces = net.Interfaces
+
+		c, _ := m.SelectedInterface()
+		m.DownloadRate = c.RecvBytesPerSec
+		m.UploadRate = c.SentBytesPerSec
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab":
+			m.Selected = m.nextInterface(1)
+		case "shift+tab":
+			m.Selected = m.nextInterface(-1)
+		case "v":
+			m.ShowVirtual = !m.ShowVirtual
+			if c, ok := m.SelectedInterface(); ok && c.Virtual && !m.ShowVirtual {
+				m.Selected = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+// updateSignalHistory returns a copy of history with the signal of each
+// connected link appended. Disconnected interfaces start over.
+func updateSignalHistory(history map[string][]float64, sample collector.WirelessSample) map[string][]float64 {
+	updated := make(map[string][]float64, len(sample.Links))
+	for _, l := range sample.Links {
+		if !l.Connected {
+			continue
+		}
+		prev := history[l.Interface]
+		prev = prev[max(0, len(prev)-signalHistory+1):]
+		updated[l.Interface] = append(append([]float64(nil), prev...), l.Signal)
+	}
+	return updated
+}
 
-		// Calculate rates
-		duration := msg.timestamp.Sub(m.lastCheck).Seconds()
-		if duration > 0 {
-			m.DownloadRate = float64(msg.bytesRecv-m.lastBytesRecv) / duration
-			m.UploadRate = float64(msg.bytesSent-m.lastBytesSent) / duration
+// VisibleInterfaces returns the interfaces to list: hardware ones first,
+// then the virtual ones if they are shown.
+func (m NetworkModel) VisibleInterfaces() []collector.InterfaceStat {
+	var physical, virtual []collector.InterfaceStat
+	for _, c := range m.Interfaces {
+		if c.Virtual {
+			virtual = append(virtual, c)
+		} else {
+			physical = append(physical, c)
 		}
+	}
+	if !m.ShowVirtual {
+		return physical
+	}
+	return append(physical, virtual...)
+}
 
-		m.lastBytesRecv = msg.bytesRecv
-		m.lastBytesSent = msg.bytesSent
-		m.lastCheck = msg.timestamp
+// SelectedInterface returns the interface shown in detail.
+func (m NetworkModel) SelectedInterface() (collector.InterfaceStat, bool) {
+	name := m.Selected
+	if name == "" {
+		name = m.Interface
+	}
+	for _, c := range m.Interfaces {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	if visible := m.VisibleInterfaces(); len(visible) > 0 {
+		return visible[0], true
+	}
+	return collector.InterfaceStat{}, false
+}
 
-		if m.Polling {
-			return m, getNetworkTick(m.Id, m.Interface)
+// nextInterface returns the name of the visible interface step places away
+// from the selected one, wrapping around.
+func (m NetworkModel) nextInterface(step int) string {
+	visible := m.VisibleInterfaces()
+	if len(visible) == 0 {
+		return m.Selected
+	}
+	cur, _ := m.SelectedInterface()
+	for i, c := range visible {
+		if c.Name == cur.Name {
+			return visible[(i+step+len(visible))%len(visible)].Name
 		}
 	}
-	return m, nil
+	return visible[0].Name
 }
 
 func (m NetworkModel) View() string {
@@ -177,23 +221,16 @@ func (m NetworkModel) View() string {
 		ipv6Status = styles.StatValueStyle.Foreground(styles.ColorError).Render("Enabled")
 	}
 
-	netTypeDisplay := m.NetType
-	if m.NetType == "WiFi" && m.WifiBand != "" {
-		netTypeDisplay += fmt.Sprintf(" (%s)", m.WifiBand)
-	}
-
 	content := lipgloss.JoinVertical(lipgloss.Left,
-		styles.RenderStat("󰈀 Interface:", m.Interface),
-		styles.RenderStat(" Type:", netTypeDisplay),
-		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render("󰅐 IPv6:"), ipv6Status),
+		m.renderInterfaces(),
 		"",
-		styles.RenderStat(" Download:", formatSpeed(m.DownloadRate)),
-		styles.RenderStat(" Upload:", formatSpeed(m.UploadRate)),
+		m.renderSelected(ipv6Status),
 		"",
-		styles.RenderStat(" Total Rx:", formatSize(m.lastBytesRecv)),
-		styles.RenderStat(" Total Tx:", formatSize(m.lastBytesSent)),
+		m.renderProcesses(),
 		"",
 		m.renderSpeedtestSection(),
+		"",
+		m.renderHelp(),
 	)
 
 	box := styles.StatBoxStyle.Render(content)
@@ -202,6 +239,198 @@ func (m NetworkModel) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, title, box)
 }
 
+func (m NetworkModel) renderInterfaces() string {
+	header := styles.TableHeaderStyle.Padding(0).Render(fmt.Sprintf("%-14s %-7s %11s %11s %15s %9s", "INTERFACE", "STATE", "RX/s", "TX/s", "PKTS/s RX/TX", "ERR/DROP"))
+	lines := []string{header}
+
+	selected, _ := m.SelectedInterface()
+	virtual := false
+	for _, c := range m.VisibleInterfaces() {
+		if c.Virtual && !virtual {
+			virtual = true
+			lines = append(lines, styles.StatKeyStyle.Render("virtual"))
+		}
+		row := fmt.Sprintf("%-14s %-7s %11s %11s %15s %9s",
+			truncate(c.Name, 14), truncate(c.State, 7), formatSpeed(c.RecvBytesPerSec), formatSpeed(c.SentBytesPerSec),
+			fmt.Sprintf("%.0f/%.0f", c.RecvPacketsPerSec, c.SentPacketsPerSec),
+			fmt.Sprintf("%d/%d", c.ErrorsIn+c.ErrorsOut, c.DropsIn+c.DropsOut))
+		if c.Name == selected.Name {
+			lines = append(lines, styles.TableSelectedStyle.Render(row))
+		} else {
+			lines = append(lines, styles.TableCellStyle.Padding(0).Render(row))
+		}
+	}
+	if len(lines) == 1 {
+		lines = append(lines, styles.StatKeyStyle.Render("No interfaces..."))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderProcesses lists the processes moving the most data.
+func (m NetworkModel) renderProcesses() string {
+	header := styles.TableHeaderStyle.Padding(0).Render(fmt.Sprintf("%-8s %-24s %11s %11s %5s  %-18s", "PID", "PROCESS", "RX/s", "TX/s", "TCP", "NAMESPACE"))
+	lines := []string{header}
+
+	for _, p := range m.Processes.Processes[:min(topProcesses, len(m.Processes.Processes))] {
+		tcp, namespace := strconv.Itoa(p.Connections), "host"
+		if p.Namespace != "" {
+			// The counters of a container cover all of its processes
+			tcp, namespace = "-", fmt.Sprintf("own (%d procs)", p.Processes)
+		}
+		row := fmt.Sprintf("%-8d %-24s %11s %11s %5s  %-18s",
+			p.Pid, truncate(p.Name, 24), formatSpeed(p.RecvBytesPerSec), formatSpeed(p.SentBytesPerSec), tcp, namespace)
+		lines = append(lines, styles.TableCellStyle.Padding(0).Render(row))
+	}
+	if len(lines) == 1 {
+		lines = append(lines, styles.StatKeyStyle.Render("No processes..."))
+	}
+	if m.Processes.SocketErr != "" {
+		lines = append(lines, styles.StatValueStyle.Foreground(styles.ColorWarning).Render(truncate("Per-socket counters unavailable: "+m.Processes.SocketErr, 84)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderSelected shows everything known about the selected interface.
+func (m NetworkModel) renderSelected(ipv6Status string) string {
+	c, ok := m.SelectedInterface()
+	if !ok {
+		if m.Interface == "" {
+			return styles.RenderStat("󰈀 Interface:", "None")
+		}
+		return styles.RenderStat("󰈀 Interface:", m.Interface)
+	}
+
+	name := c.Name
+	netType := "Wired"
+	switch {
+	case c.Name == m.Interface:
+		name += " (default route)"
+		netType = m.NetType
+	case c.WiFi:
+		netType = "WiFi"
+	case c.Virtual:
+		netType = "Virtual"
+	}
+
+	link, _ := m.Wireless.Link(c.Name)
+	if netType == "WiFi" {
+		band := m.WifiBand
+		if link.Band != "" || c.Name != m.Interface {
+			band = link.Band
+		}
+		if band != "" {
+			netType += fmt.Sprintf(" (%s)", band)
+		}
+	}
+
+	addrs := "None"
+	if len(c.Addrs) > 0 {
+		addrs = strings.Join(c.Addrs, ", ")
+	}
+	var gateways []string
+	for _, r := range []collector.Route{m.IPv4Route, m.IPv6Route} {
+		if r.Interface == c.Name {
+			gw := r.Gateway
+			if gw == "" {
+				gw = "on-link"
+			}
+			gateways = append(gateways, gw)
+		}
+	}
+	gateway := "None"
+	if len(gateways) > 0 {
+		gateway = strings.Join(gateways, ", ")
+	}
+
+	lines := []string{
+		styles.RenderStat("󰈀 Interface:", name),
+		styles.RenderStat(" Type:", netType),
+		styles.RenderStat("  State / MTU:", fmt.Sprintf("%s / %d", c.State, c.MTU)),
+		styles.RenderStat("  MAC:", c.MAC),
+		styles.RenderStat("  Addresses:", truncate(addrs, 50)),
+		styles.RenderStat("  Default Gateway:", truncate(gateway, 50)),
+		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render("󰅐 IPv6:"), ipv6Status),
+	}
+	if c.WiFi {
+		lines = append(lines, "", m.renderWireless(link))
+	}
+	lines = append(lines,
+		"",
+		styles.RenderStat(" Download:", formatSpeed(c.RecvBytesPerSec)),
+		styles.RenderStat(" Upload:", formatSpeed(c.SentBytesPerSec)),
+		styles.RenderStat("  Packets Rx / Tx:", fmt.Sprintf("%.0f/s / %.0f/s", c.RecvPacketsPerSec, c.SentPacketsPerSec)),
+		styles.RenderStat("  Errors In / Out:", fmt.Sprintf("%d / %d", c.ErrorsIn, c.ErrorsOut)),
+		styles.RenderStat("  Drops In / Out:", fmt.Sprintf("%d / %d", c.DropsIn, c.DropsOut)),
+		"",
+		styles.RenderStat(" Total Rx:", formatSize(c.BytesRecv)),
+		styles.RenderStat(" Total Tx:", formatSize(c.BytesSent)),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderWireless shows the WiFi link of the selected interface.
+func (m NetworkModel) renderWireless(l collector.WirelessLink) string {
+	if !l.Connected {
+		return styles.RenderStat("󰖩 WiFi:", "Not connected")
+	}
+
+	ssid := l.SSID
+	if ssid == "" {
+		ssid = "Unknown"
+	}
+	if l.BSSID != "" {
+		ssid += " (" + l.BSSID + ")"
+	}
+	freq := "Unknown"
+	if l.Frequency > 0 {
+		freq = fmt.Sprintf("%.0f MHz", l.Frequency)
+		if l.Channel > 0 {
+			freq += fmt.Sprintf(" • Channel %d", l.Channel)
+		}
+	}
+	bitrate := "Unknown"
+	if l.RxBitrate > 0 || l.TxBitrate > 0 {
+		bitrate = fmt.Sprintf("%.1f / %.1f Mbit/s", l.RxBitrate, l.TxBitrate)
+	}
+
+	// Usable links are roughly between -90 dBm and -30 dBm
+	signal := styles.StatValueStyle.Foreground(signalColor(l.Quality)).Render(fmt.Sprintf("%.0f dBm (%.0f%%)", l.Signal, l.Quality)) +
+		" " + styles.RenderSparkline(m.SignalHistory[l.Interface], -90, -30)
+
+	lines := []string{
+		styles.RenderStat("󰖩 SSID:", truncate(ssid, 50)),
+		styles.RenderStat("  Frequency:", freq),
+		lipgloss.JoinHorizontal(lipgloss.Left, styles.StatKeyStyle.Render("󰤨 Signal:"), signal),
+		styles.RenderStat("  Bitrate Rx / Tx:", bitrate),
+	}
+	if l.Err != "" {
+		// Only /proc/net/wireless could be read
+		lines = append(lines, styles.HelpStyle.Margin(0).Render(truncate(l.Err, 80)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func signalColor(quality float64) lipgloss.Color {
+	switch {
+	case quality >= 60:
+		return styles.ColorSuccess
+	case quality >= 30:
+		return styles.ColorWarning
+	}
+	return styles.ColorError
+}
+
+func (m NetworkModel) renderHelp() string {
+	help := "[Tab] Next interface • [v] Show virtual"
+	if m.ShowVirtual {
+		help = "[Tab] Next interface • [v] Hide virtual"
+	}
+	if hidden := len(m.Interfaces) - len(m.VisibleInterfaces()); hidden > 0 {
+		help = fmt.Sprintf("%d virtual hidden • %s", hidden, help)
+	}
+	return styles.HelpStyle.Margin(0).Render(help)
+}
+
 func (m NetworkModel) renderSpeedtestSection() string {
 	if m.IsSpeedtesting {
 		return styles.StatKeyStyle.Render("󰾆 Speedtest: Running...")
@@ -210,15 +439,51 @@ func (m NetworkModel) renderSpeedtestSection() string {
 		return styles.StatKeyStyle.Render("󰾆 Speedtest: Waiting...")
 	}
 
-	val := fmt.Sprintf("%.2f Mbps", m.SpeedtestDownload)
+	if errors.Is(m.SpeedtestErr, speedtest.ErrNoURL) {
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			styles.StatKeyStyle.Render("󰾆 Speedtest:"),
+			styles.HelpStyle.Margin(0, 0).Render("Not configured, set SPEEDTEST_URL"),
+		)
+	}
+
+	r := m.SpeedtestResult
+	val := fmt.Sprintf("↓ %.2f Mbps", r.Download)
+	if r.UploadBytes > 0 {
+		val += fmt.Sprintf("  ↑ %.2f Mbps", r.Upload)
+	}
+	color := styles.ColorCyan
 	timeStr := fmt.Sprintf("(at %s)", m.SpeedtestTime)
+	if m.SpeedtestErr != nil {
+		val, color = "Failed", styles.ColorError
+		timeStr = fmt.Sprintf("(at %s: %s)", m.SpeedtestTime, truncate(m.SpeedtestErr.Error(), 40))
+	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Left,
+	speed := lipgloss.JoinHorizontal(lipgloss.Left,
 		styles.StatKeyStyle.Render("󰾆 Speedtest:"),
-		styles.StatValueStyle.Foreground(styles.ColorCyan).Render(val),
+		styles.StatValueStyle.Foreground(color).Render(val),
 		" ",
 		styles.HelpStyle.Margin(0, 0).Render(timeStr),
 	)
+	if m.SpeedtestErr != nil {
+		return speed
+	}
+	lines := []string{
+		speed,
+		styles.RenderStat("  Latency:", fmt.Sprintf("%s ± %s jitter", formatLatency(r.Latency), formatLatency(r.Jitter))),
+	}
+	if r.UploadErr != nil {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+			styles.StatKeyStyle.Render("  Upload:"),
+			styles.StatValueStyle.Foreground(styles.ColorError).Render("Failed"),
+			" ",
+			styles.HelpStyle.Margin(0, 0).Render("("+truncate(strings.TrimPrefix(r.UploadErr.Error(), "upload: "), 44)+")"),
+		))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1f ms", float64(d)/float64(time.Millisecond))
 }
 
 func formatSpeed(bytesPerSec float64) string {
@@ -243,52 +508,13 @@ func formatSize(bytes uint64) string {
 	}
 }
 
-func getNetworkTick(id int, iface string) tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return collectNetworkData(id, iface)
-	})
-}
-
-func collectNetworkData(id int, iface string) tea.Msg {
-	counters, _ := psnet.IOCounters(true)
-	var recv, sent uint64
-	for _, c := range counters {
-		if c.Name == iface {
-			recv = c.BytesRecv
-			sent = c.BytesSent
-			break
-		}
-	}
-	return NetTickMsg{
-		id:        id,
-		bytesRecv: recv,
-		bytesSent: sent,
-		timestamp: time.Now(),
-	}
-}
-
-func runSpeedtest(id int) tea.Cmd {
+// runSpeedtest tests the throughput to the server of cfg, giving up when
+// it takes far longer than planned.
+func runSpeedtest(id int, cfg speedtest.Config) tea.Cmd {
 	return func() tea.Msg {
-		// speedtest-cli --csv
-		out, err := exec.Command("speedtest-cli", "--csv", "--no-upload", "--server", "17391").Output()
-		if err != nil {
-			return SpeedtestMsg{Id: id, Download: 0}
-		}
-
-		fields := strings.Split(string(out), ",")
-		if len(fields) < 7 {
-			return SpeedtestMsg{Id: id, Download: 0}
-		}
-
-		// Download speed is 7th field in bits/s
-		downloadBits, err := strconv.ParseFloat(strings.TrimSpace(fields[6]), 64)
-		if err != nil {
-			return SpeedtestMsg{Id: id, Download: 0}
-		}
-
-		return SpeedtestMsg{
-			Id:       id,
-			Download: downloadBits / 1000000.0, // convert to Mbps
-		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*cfg.Duration+time.Minute)
+		defer cancel()
+		r, err := speedtest.Run(ctx, cfg)
+		return SpeedtestMsg{Id: id, Result: r, Err: err}
 	}
 }
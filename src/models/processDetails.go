@@ -2,196 +2,595 @@ package models
 
 import (
 	"fmt"
-	"os/exec"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
+	"syscall"
 
+	"go-test/src/collector"
+	"go-test/src/procctl"
 	"go-test/src/styles"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-type ProcessItem struct {
-	Pid   string
-	Name  string
-	Value float64
-	Unit  string
+type processColumn int
+
+const (
+	columnPid processColumn = iota
+	columnUser
+	columnState
+	columnThreads
+	columnCpu
+	columnRss
+	columnGpu
+	columnCommand
+)
+
+type columnSpec struct {
+	title string
+	width int
+	right bool
 }
 
-type ProcessMsg struct {
-	id     int
-	CpuTop []ProcessItem
-	RamTop []ProcessItem
+// Column layout; the widths add up to the inner width of StatBoxStyle.
+var processColumns = []columnSpec{
+	columnPid:     {"PID", 8, true},
+	columnUser:    {"USER", 10, false},
+	columnState:   {"S", 3, false},
+	columnThreads: {"THR", 5, true},
+	columnCpu:     {"CPU%", 7, true},
+	columnRss:     {"RSS", 11, true},
+	columnGpu:     {"GPU", 11, true},
+	columnCommand: {"COMMAND", 27, false},
 }
 
+const processPageSize = 10
+
 type ProcessModel struct {
-	Id      int
-	CpuTop  []ProcessItem
-	RamTop  []ProcessItem
-	Polling bool
+	Processes []collector.ProcessStat // every process, unsorted
+	GpuMemory map[int]uint64          // bytes of GPU memory, keyed by pid
+
+	SortBy   processColumn
+	SortDesc bool
+
+	Filter    string
+	Filtering bool // the filter prompt has focus
+
+	TreeMode  bool
+	collapsed map[int]bool // pids whose children are hidden in tree mode
+
+	cursor     int // index into the visible rows
+	offset     int // first visible row
+	selectedId int // pid under the cursor, kept across refreshes
+
+	pending     *processAction // waiting for confirmation
+	editingNice bool
+	niceInput   string
+	lastResult  string
+	lastErr     error
 }
 
-func NewProcessModel() ProcessModel {
-	return ProcessModel{
-		Id: 0,
+// processAction is a signal or renice request for one process.
+type processAction struct {
+	pid    int
+	name   string
+	signal string // signal name, empty for a renice
+	nice   int
+}
+
+func (a processAction) String() string {
+	if a.signal != "" {
+		return fmt.Sprintf("send SIG%s to %d (%s)", a.signal, a.pid, a.name)
 	}
+	return fmt.Sprintf("renice %d (%s) to %d", a.pid, a.name, a.nice)
 }
 
-func (m ProcessModel) Init() tea.Cmd {
-	if m.Polling {
-		return getProcessStats(m.Id)
+type ProcessActionMsg struct {
+	action processAction
+	err    error
+}
+
+func NewProcessModel() ProcessModel {
+	return ProcessModel{
+		SortBy:    columnCpu,
+		SortDesc:  true,
+		collapsed: make(map[int]bool),
 	}
-	return nil
 }
 
 func (m ProcessModel) Update(msg tea.Msg) (ProcessModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case ProcessMsg:
-		if msg.id != m.Id {
+	case SampleMsg:
+		if msg.Name == collector.Gpu {
+			gpus, _ := msg.Sample.(collector.GpuSample)
+			m.GpuMemory = gpuMemory(gpus)
 			return m, nil
 		}
-		m.CpuTop = msg.CpuTop
-		m.RamTop = msg.RamTop
-
-		if m.Polling {
-			return m, getProcessStats(m.Id)
+		if msg.Name != collector.Processes {
+			return m, nil
+		}
+		if sample, ok := msg.Sample.(collector.ProcessSample); ok && msg.Err == nil {
+			m.Processes = sample.Processes
+		} else {
+			m.Processes = nil
+		}
+		m.followSelection()
+	case ProcessActionMsg:
+		m.lastErr = msg.err
+		m.lastResult = ""
+		if msg.err == nil {
+			m.lastResult = "Done: " + msg.action.String()
+		}
+	case tea.KeyMsg:
+		if m.pending != nil {
+			return m.updateConfirm(msg)
+		}
+		if m.editingNice {
+			return m.updateNiceInput(msg), nil
+		}
+		if m.Filtering {
+			return m.updateFilter(msg), nil
 		}
+		m = m.updateKeys(msg)
 	}
 	return m, nil
 }
 
-func (m ProcessModel) View() string {
-	title := styles.TitleStyle.Render("TOP PROCESSES")
+// Capturing reports whether the page is consuming all key presses, e.g.
+// while typing a filter or answering a confirmation.
+func (m ProcessModel) Capturing() bool {
+	return m.Filtering || m.editingNice || m.pending != nil
+}
 
-	// Render tables
-	// Render tables
-	t1 := renderTable(" Top CPU", "Usage %", m.CpuTop)
-	t2 := renderTable(" Top RAM", "Usage %", m.RamTop)
+func (m ProcessModel) updateConfirm(msg tea.KeyMsg) (ProcessModel, tea.Cmd) {
+	action := *m.pending
+	m.pending = nil
 
-	// Add separator
-	t1 = lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder(), false, true, false, false).
-		BorderForeground(styles.ColorSubtext).
-		PaddingRight(1).
-		Render(t1)
+	switch msg.String() {
+	case "y", "Y":
+		return m, runProcessAction(action)
+	}
+	return m, nil
+}
 
-	t2 = lipgloss.NewStyle().PaddingLeft(1).Render(t2)
+func (m ProcessModel) updateNiceInput(msg tea.KeyMsg) ProcessModel {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.editingNice = false
+		nice, err := strconv.Atoi(m.niceInput)
+		if err != nil || nice < procctl.MinNice || nice > procctl.MaxNice {
+			m.lastResult = ""
+			m.lastErr = fmt.Errorf("invalid nice value %q, want %d to %d", m.niceInput, procctl.MinNice, procctl.MaxNice)
+			return m
+		}
+		if p, ok := m.Selected(); ok {
+			m.pending = &processAction{pid: p.Pid, name: p.Name, nice: nice}
+		}
+	case tea.KeyEsc:
+		m.editingNice = false
+	case tea.KeyBackspace:
+		if len(m.niceInput) > 0 {
+			m.niceInput = m.niceInput[:len(m.niceInput)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if (r >= '0' && r <= '9') || (r == '-' && m.niceInput == "") {
+				m.niceInput += string(r)
+			}
+		}
+	}
+	return m
+}
 
-	// Grid layout
-	topRow := lipgloss.JoinHorizontal(lipgloss.Top, t1, t2)
-	grid := lipgloss.JoinVertical(lipgloss.Left, topRow)
+func runProcessAction(a processAction) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if a.signal != "" {
+			var sig syscall.Signal
+			if sig, err = procctl.ParseSignal(a.signal); err == nil {
+				err = procctl.Signal(a.pid, sig)
+			}
+		} else {
+			err = procctl.Renice(a.pid, a.nice)
+		}
+		return ProcessActionMsg{action: a, err: err}
+	}
+}
 
-	box := styles.StatBoxStyle.Render(grid)
+func (m ProcessModel) updateFilter(msg tea.KeyMsg) ProcessModel {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.Filtering = false
+	case tea.KeyEsc:
+		m.Filtering = false
+		m.Filter = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.Filter); len(r) > 0 {
+			m.Filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.Filter += string(msg.Runes)
+	default:
+		return m
+	}
+	m.cursor, m.offset = 0, 0
+	m.followCursor()
+	return m
+}
 
-	return lipgloss.JoinVertical(lipgloss.Left, title, box)
+func (m ProcessModel) updateKeys(msg tea.KeyMsg) ProcessModel {
+	rows := len(m.Rows())
+
+	switch key := msg.String(); key {
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= processPageSize
+	case "pgdown":
+		m.cursor += processPageSize
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = rows - 1
+	case "/":
+		m.Filtering = true
+		return m
+	case "T", "K", "S", "C":
+		if p, ok := m.Selected(); ok {
+			signal := map[string]string{"T": "TERM", "K": "KILL", "S": "STOP", "C": "CONT"}[key]
+			m.pending = &processAction{pid: p.Pid, name: p.Name, signal: signal}
+		}
+		return m
+	case "t":
+		m.TreeMode = !m.TreeMode
+		m.followSelection()
+		return m
+	case "left", "h":
+		if p, ok := m.Selected(); ok && m.TreeMode {
+			m.collapsed[p.Pid] = true
+		}
+	case "right", "l":
+		if p, ok := m.Selected(); ok && m.TreeMode {
+			delete(m.collapsed, p.Pid)
+		}
+	case "n":
+		if p, ok := m.Selected(); ok {
+			m.editingNice = true
+			m.niceInput = strconv.Itoa(p.Nice)
+		}
+		return m
+	case "esc":
+		m.Filter = ""
+	case "1", "2", "3", "4", "5", "6", "7", "8":
+		col := processColumn(key[0] - '1')
+		if m.SortBy == col {
+			m.SortDesc = !m.SortDesc
+		} else {
+			m.SortBy = col
+			// Numbers read best largest first, text alphabetically
+			m.SortDesc = col == columnThreads || col == columnCpu || col == columnRss || col == columnGpu
+		}
+	default:
+		return m
+	}
+
+	m.followCursor()
+	return m
 }
 
-func renderTable(titleStr, valueLabel string, items []ProcessItem) string {
-	// Column widths
-	nameWidth := 20
-	pidWidth := 10
-	valWidth := 10
+// followCursor clamps the cursor, scrolls it into view and remembers the
+// selected pid.
+func (m *ProcessModel) followCursor() {
+	rows := m.Rows()
+	if m.cursor >= len(rows) {
+		m.cursor = len(rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+processPageSize {
+		m.offset = m.cursor - processPageSize + 1
+	}
 
-	// Column styles
-	nameCol := styles.TableCellStyle.Width(nameWidth)
-	pidCol := styles.TableCellStyle.Width(pidWidth)
-	valCol := styles.TableCellStyle.Width(valWidth).Align(lipgloss.Right)
+	m.selectedId = 0
+	if len(rows) > 0 {
+		m.selectedId = rows[m.cursor].Pid
+	}
+}
 
-	headerStyle := styles.TableHeaderStyle.Border(lipgloss.NormalBorder(), false, false, true, false).BorderForeground(styles.ColorSubtext)
+// followSelection moves the cursor to wherever the selected process ended up
+// after a refresh re-sorted the rows.
+func (m *ProcessModel) followSelection() {
+	for i, p := range m.Rows() {
+		if p.Pid == m.selectedId {
+			m.cursor = i
+			break
+		}
+	}
+	m.followCursor()
+}
+
+// Selected returns the process under the cursor.
+func (m ProcessModel) Selected() (collector.ProcessStat, bool) {
+	rows := m.Rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return collector.ProcessStat{}, false
+	}
+	return rows[m.cursor].ProcessStat, true
+}
 
-	// Header
-	header := lipgloss.JoinHorizontal(lipgloss.Left,
-		headerStyle.Width(nameWidth).Render("Name"),
-		headerStyle.Width(pidWidth).Render("PID"),
-		headerStyle.Width(valWidth).Align(lipgloss.Right).Render(valueLabel),
-	)
+// processRow is one line of the table. In tree mode it also carries the
+// branch drawing and the totals of the whole subtree.
+type processRow struct {
+	collector.ProcessStat
+
+	prefix    string
+	children  int
+	collapsed bool
+	treeCpu   float64
+	treeRss   uint64
+	gpuMemory uint64
+}
 
-	// Title + Header + Rows
-	rows := []string{
-		styles.StatKeyStyle.Bold(true).Foreground(styles.ColorPrimary).MarginBottom(1).Render(titleStr),
-		header,
+// Rows returns the processes matching the filter, in display order.
+func (m ProcessModel) Rows() []processRow {
+	if m.TreeMode {
+		return m.treeRows()
 	}
 
-	if len(items) == 0 {
-		rows = append(rows, styles.StatKeyStyle.Render("No data..."))
-		rows = append(rows, styles.StatKeyStyle.Render("No data..."))
-		rows = append(rows, styles.StatKeyStyle.Render("No data..."))
-		rows = append(rows, styles.StatKeyStyle.Render("No data..."))
-		rows = append(rows, styles.StatKeyStyle.Render("No data..."))
+	filter := strings.ToLower(m.Filter)
+	rows := make([]processRow, 0, len(m.Processes))
+	for _, p := range m.Processes {
+		if filter == "" || processMatches(p, filter) {
+			rows = append(rows, processRow{ProcessStat: p, gpuMemory: m.GpuMemory[p.Pid]})
+		}
 	}
 
-	for i, item := range items {
-		if i >= 5 {
-			break
+	m.sortRows(rows)
+	return rows
+}
+
+func (m ProcessModel) sortRows(rows []processRow) {
+	statLess := processLess(m.SortBy)
+	less := func(a, b processRow) bool { return statLess(a.sortKey(), b.sortKey()) }
+	if m.SortBy == columnGpu {
+		// GPU memory comes from another collector, so it is not part of
+		// ProcessStat
+		less = func(a, b processRow) bool {
+			if a.gpuMemory == b.gpuMemory {
+				return a.Pid < b.Pid
+			}
+			return a.gpuMemory < b.gpuMemory
 		}
-		name := item.Name
-		if len(name) > nameWidth-2 {
-			name = name[:nameWidth-3] + "…"
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if m.SortDesc {
+			return less(rows[j], rows[i])
 		}
+		return less(rows[i], rows[j])
+	})
+}
+
+// gpuMemory totals the GPU memory used by each process over all GPUs.
+func gpuMemory(gpus collector.GpuSample) map[int]uint64 {
+	mem := make(map[int]uint64)
+	for _, g := range gpus.Gpus {
+		for _, p := range g.Processes {
+			mem[p.Pid] += uint64(p.UsedMemory * (1 << 20))
+		}
+	}
+	return mem
+}
 
-		valStr := fmt.Sprintf("%.1f", item.Value)
+func processMatches(p collector.ProcessStat, filter string) bool {
+	return strings.Contains(strings.ToLower(p.Name), filter) ||
+		strings.Contains(strings.ToLower(p.Cmdline), filter) ||
+		strings.Contains(strings.ToLower(p.User), filter) ||
+		strings.Contains(strconv.Itoa(p.Pid), filter)
+}
 
-		row := lipgloss.JoinHorizontal(lipgloss.Left,
-			nameCol.Render(name),
-			pidCol.Render(item.Pid),
-			valCol.Render(valStr),
-		)
-		rows = append(rows, row)
+func processLess(col processColumn) func(a, b collector.ProcessStat) bool {
+	byPid := func(a, b collector.ProcessStat) bool { return a.Pid < b.Pid }
+	switch col {
+	case columnUser:
+		return func(a, b collector.ProcessStat) bool {
+			if a.User == b.User {
+				return byPid(a, b)
+			}
+			return a.User < b.User
+		}
+	case columnState:
+		return func(a, b collector.ProcessStat) bool {
+			if a.State == b.State {
+				return byPid(a, b)
+			}
+			return a.State < b.State
+		}
+	case columnThreads:
+		return func(a, b collector.ProcessStat) bool {
+			if a.Threads == b.Threads {
+				return byPid(a, b)
+			}
+			return a.Threads < b.Threads
+		}
+	case columnCpu:
+		return func(a, b collector.ProcessStat) bool {
+			if a.CpuPercent == b.CpuPercent {
+				return byPid(a, b)
+			}
+			return a.CpuPercent < b.CpuPercent
+		}
+	case columnRss:
+		return func(a, b collector.ProcessStat) bool {
+			if a.RSS == b.RSS {
+				return byPid(a, b)
+			}
+			return a.RSS < b.RSS
+		}
+	case columnCommand:
+		return func(a, b collector.ProcessStat) bool {
+			ca, cb := processCommand(a), processCommand(b)
+			if ca == cb {
+				return byPid(a, b)
+			}
+			return ca < cb
+		}
 	}
+	return byPid
+}
 
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+// processCommand returns the full command line, or the name in brackets for
+// kernel threads, like ps does.
+func processCommand(p collector.ProcessStat) string {
+	if p.Cmdline == "" {
+		return "[" + p.Name + "]"
+	}
+	return p.Cmdline
 }
 
-func collectProcessData(id int) tea.Msg {
-	cpuTop := fetchPsData("pcpu")
-	ramTop := fetchPsData("pmem")
+func (m ProcessModel) View() string {
+	title := styles.TitleStyle.Render("PROCESSES")
+
+	rows := m.Rows()
 
-	return ProcessMsg{
-		id:     id,
-		CpuTop: cpuTop,
-		RamTop: ramTop,
+	headerStyle := styles.TableHeaderStyle.Border(lipgloss.NormalBorder(), false, false, true, false).BorderForeground(styles.ColorSubtext)
+	headers := make([]string, len(processColumns))
+	for i, col := range processColumns {
+		label := col.title
+		if m.TreeMode && (processColumn(i) == columnCpu || processColumn(i) == columnRss) {
+			// Tree mode shows totals of the whole subtree
+			label = "Σ" + label
+		}
+		if processColumn(i) == m.SortBy {
+			if m.SortDesc {
+				label += "▼"
+			} else {
+				label += "▲"
+			}
+		}
+		style := headerStyle.Width(col.width)
+		if col.right {
+			style = style.Align(lipgloss.Right)
+		}
+		headers[i] = style.Render(label)
+	}
+
+	lines := []string{lipgloss.JoinHorizontal(lipgloss.Left, headers...)}
+
+	if len(rows) == 0 {
+		lines = append(lines, styles.StatKeyStyle.Render("No data..."))
+	}
+
+	end := m.offset + processPageSize
+	if end > len(rows) {
+		end = len(rows)
+	}
+	for i := m.offset; i < end; i++ {
+		line := renderProcessRow(rows[i], m.TreeMode)
+		if i == m.cursor {
+			line = styles.TableSelectedStyle.Render(line)
+		} else {
+			line = styles.TableCellStyle.Padding(0).Render(line)
+		}
+		lines = append(lines, line)
 	}
+
+	lines = append(lines, "", m.renderStatusLine(len(rows)))
+
+	box := styles.StatBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, box)
 }
 
-func fetchPsData(sortKey string) []ProcessItem {
-	cmd := exec.Command("ps", "-eo", "pid,comm,"+sortKey, "--no-headers")
-	out, err := cmd.Output()
-	if err != nil {
-		return []ProcessItem{}
+func renderProcessRow(p processRow, tree bool) string {
+	cells := []string{
+		columnPid:     strconv.Itoa(p.Pid),
+		columnUser:    p.User,
+		columnState:   p.State,
+		columnThreads: strconv.Itoa(p.Threads),
+		columnCpu:     fmt.Sprintf("%.1f", p.CpuPercent),
+		columnRss:     formatSize(p.RSS),
+		columnGpu:     "-",
+		columnCommand: processCommand(p.ProcessStat),
 	}
+	if p.gpuMemory > 0 {
+		cells[columnGpu] = formatSize(p.gpuMemory)
+	}
+	if tree {
+		cells[columnCpu] = fmt.Sprintf("%.1f", p.treeCpu)
+		cells[columnRss] = formatSize(p.treeRss)
 
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	var items []ProcessItem
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		if len(fields) >= 3 {
-			val, _ := strconv.ParseFloat(fields[2], 64)
-			items = append(items, ProcessItem{
-				Pid:   fields[0],
-				Name:  fields[1],
-				Value: val,
-				Unit:  "%",
-			})
+		marker := ""
+		if p.collapsed && p.children > 0 {
+			marker = fmt.Sprintf("[+%d] ", p.children)
 		}
+		cells[columnCommand] = p.prefix + marker + p.Name
 	}
 
-	// Sort descending
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Value > items[j].Value
-	})
+	var b strings.Builder
+	for i, col := range processColumns {
+		// Pad each cell by one space on both sides, like the headers
+		width := col.width - 2
+		text := truncate(cells[i], width)
+		if col.right {
+			fmt.Fprintf(&b, " %*s ", width, text)
+		} else {
+			fmt.Fprintf(&b, " %-*s ", width, text)
+		}
+	}
+	return b.String()
+}
+
+func (m ProcessModel) renderStatusLine(rows int) string {
+	position := fmt.Sprintf("%d/%d", min(m.cursor+1, rows), rows)
+	if len(m.Processes) != rows {
+		position += fmt.Sprintf(" (of %d)", len(m.Processes))
+	}
+
+	if m.pending != nil {
+		prompt := "Really " + m.pending.String() + "?"
+		return styles.StatValueStyle.Foreground(styles.ColorWarning).Render(prompt) + "  " + styles.HelpStyle.Margin(0).Render("[y] Yes • [n] No")
+	}
+	if m.editingNice {
+		return styles.StatValueStyle.Render("Nice value: "+m.niceInput+"█") + "  " + styles.HelpStyle.Margin(0).Render("[Enter] Apply • [Esc] Cancel")
+	}
+	if m.Filtering {
+		return styles.StatValueStyle.Render("/"+m.Filter+"█") + "  " + styles.HelpStyle.Margin(0).Render("[Enter] Apply • [Esc] Clear")
+	}
+
+	help := "[j/k] Move • [PgUp/PgDn] Page • [1-8] Sort • [/] Filter • [t] Tree"
+	if m.TreeMode {
+		help += " • [h/l] Fold"
+	}
+	if m.Filter != "" {
+		help = fmt.Sprintf("Filter: %q • ", m.Filter) + help + " • [Esc] Clear"
+	}
+	status := styles.HelpStyle.Margin(0).Render(position + "  " + help + "\n[Enter] Details • [T/K/S/C] TERM/KILL/STOP/CONT • [n] Nice")
 
-	// Take top 5
-	if len(items) > 5 {
-		return items[:5]
+	if m.lastErr != nil {
+		status += "\n" + styles.StatValueStyle.Foreground(styles.ColorError).Render(m.lastErr.Error())
+	} else if m.lastResult != "" {
+		status += "\n" + styles.StatValueStyle.Foreground(styles.ColorSuccess).Render(m.lastResult)
 	}
-	return items
+	return status
 }
 
-func getProcessStats(id int) tea.Cmd {
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-		return collectProcessData(id)
-	})
+// truncate shortens s to at most width runes, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(r[:width])
+	}
+	return string(r[:width-1]) + "…"
 }
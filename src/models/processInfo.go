@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-test/src/collector"
+	"go-test/src/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const processEnvPageSize = 6
+
+// ProcessInfoModel shows the details of the process picked on the
+// processes page.
+type ProcessInfoModel struct {
+	Pid    int
+	Detail collector.ProcessDetail
+	Err    error
+	Loaded bool
+
+	source    collector.ProcessDetailer
+	envOffset int
+}
+
+// ProcessInfoMsg carries the result of loading the details of one process.
+type ProcessInfoMsg struct {
+	pid    int
+	detail collector.ProcessDetail
+	err    error
+}
+
+func NewProcessInfoModel(source collector.ProcessDetailer) ProcessInfoModel {
+	return ProcessInfoModel{source: source}
+}
+
+// Open switches the page to pid and starts loading its details.
+func (m ProcessInfoModel) Open(pid int) (ProcessInfoModel, tea.Cmd) {
+	m.Pid = pid
+	m.Detail = collector.ProcessDetail{}
+	m.Err = nil
+	m.Loaded = false
+	m.envOffset = 0
+	return m, m.load()
+}
+
+func (m ProcessInfoModel) load() tea.Cmd {
+	if m.source == nil {
+		return func() tea.Msg {
+			return ProcessInfoMsg{pid: m.Pid, err: fmt.Errorf("process details are not available")}
+		}
+	}
+	source, pid := m.source, m.Pid
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		d, err := source.Detail(ctx, pid)
+		return ProcessInfoMsg{pid: pid, detail: d, err: err}
+	}
+}
+
+func (m ProcessInfoModel) Update(msg tea.Msg) (ProcessInfoModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SampleMsg:
+		// Refresh along with the process table
+		if msg.Name == collector.Processes && m.Pid != 0 {
+			return m, m.load()
+		}
+	case ProcessInfoMsg:
+		if msg.pid != m.Pid {
+			return m, nil
+		}
+		m.Loaded = true
+		m.Err = msg.err
+		if msg.err == nil {
+			m.Detail = msg.detail
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			m.envOffset--
+		case "down", "j":
+			m.envOffset++
+		}
+		m.envOffset = max(0, min(m.envOffset, len(m.Detail.Environ)-processEnvPageSize))
+	}
+	return m, nil
+}
+
+func (m ProcessInfoModel) View() string {
+	title := styles.TitleStyle.Render(fmt.Sprintf("PROCESS %d", m.Pid))
+
+	var content string
+	switch {
+	case !m.Loaded:
+		content = styles.StatKeyStyle.Render("Loading...")
+	case m.Err != nil && m.Detail.Pid == 0:
+		content = styles.StatValueStyle.Foreground(styles.ColorError).Render(m.Err.Error())
+	default:
+		content = m.renderDetail()
+	}
+
+	help := styles.HelpStyle.Margin(0).Render("[j/k] Scroll environment • [Esc] Back • [Space] Menu")
+	box := styles.StatBoxStyle.Height(0).Render(lipgloss.JoinVertical(lipgloss.Left, content, "", help))
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, box)
+}
+
+func (m ProcessInfoModel) renderDetail() string {
+	d := m.Detail
+	unreadable := func(field string) bool { return slices.Contains(d.Unreadable, field) }
+	orNA := func(field, value string) string {
+		if unreadable(field) {
+			return "N/A"
+		}
+		return value
+	}
+
+	started := "N/A"
+	if !d.StartTime.IsZero() {
+		started = fmt.Sprintf("%s (%s ago)", d.StartTime.Format("2006-01-02 15:04:05"), time.Since(d.StartTime).Truncate(time.Second))
+	}
+
+	lines := []string{
+		styles.RenderStat("Name:", d.Name),
+		styles.RenderStat("Command:", truncate(processCommand(d.ProcessStat), 52)),
+		styles.RenderStat("User / State:", fmt.Sprintf("%s / %s", d.User, d.State)),
+		styles.RenderStat("Parent PID:", strconv.Itoa(d.PPid)),
+		styles.RenderStat("Started:", started),
+		styles.RenderStat("Cwd:", orNA("cwd", truncate(d.Cwd, 52))),
+		"",
+		styles.RenderStat("Threads / Nice:", fmt.Sprintf("%d / %d", d.Threads, d.Nice)),
+		styles.RenderStat("CPU / Memory:", fmt.Sprintf("%.1f%% / %.1f%% (RSS %s, PSS %s)", d.CpuPercent, d.MemPercent, formatSize(d.RSS), orNA("smaps", formatSize(d.PSS)))),
+		styles.RenderStat("Open Files:", orNA("fd", strconv.Itoa(d.FDCount))),
+		styles.RenderStat("I/O Read / Written:", orNA("io", fmt.Sprintf("%s / %s", formatSize(d.IO.ReadBytes), formatSize(d.IO.WriteBytes)))),
+		styles.RenderStat("Mappings:", orNA("maps", fmt.Sprintf("%d, %s total", d.Maps.Count, formatSize(d.Maps.Total)))),
+		styles.RenderStat("  File / Anon:", orNA("maps", fmt.Sprintf("%s / %s", formatSize(d.Maps.File), formatSize(d.Maps.Anon)))),
+		styles.RenderStat("  Heap / Stack:", orNA("maps", fmt.Sprintf("%s / %s", formatSize(d.Maps.Heap), formatSize(d.Maps.Stack)))),
+	}
+
+	cgroups := "N/A"
+	if !unreadable("cgroup") {
+		cgroups = strings.Join(d.Cgroups, ", ")
+	}
+	lines = append(lines, styles.RenderStat("Cgroups:", truncate(cgroups, 52)), "")
+
+	lines = append(lines, styles.StatKeyStyle.Render(fmt.Sprintf("Environment (%d):", len(d.Environ))))
+	if unreadable("environ") {
+		lines = append(lines, styles.StatValueStyle.Render("  N/A"))
+	}
+	start := min(m.envOffset, len(d.Environ))
+	end := min(start+processEnvPageSize, len(d.Environ))
+	for _, kv := range d.Environ[start:end] {
+		lines = append(lines, styles.StatValueStyle.Render("  "+truncate(kv, 80)))
+	}
+
+	if m.Err != nil {
+		lines = append(lines, "", styles.StatValueStyle.Foreground(styles.ColorError).Render(m.Err.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"strings"
+
+	"go-test/src/collector"
+)
+
+// sortKey returns the process with CPU and memory replaced by the subtree
+// totals when they are set, so tree siblings sort by their total load.
+func (r processRow) sortKey() collector.ProcessStat {
+	p := r.ProcessStat
+	if r.treeCpu != 0 || r.treeRss != 0 {
+		p.CpuPercent = r.treeCpu
+		p.RSS = r.treeRss
+	}
+	return p
+}
+
+// treeRows lays the processes out as a parent/child hierarchy. Siblings are
+// sorted by the current sort column; collapsed branches hide their
+// descendants. With a filter, a process is kept if it or any descendant
+// matches.
+func (m ProcessModel) treeRows() []processRow {
+	nodes := make(map[int]*processRow, len(m.Processes))
+	for _, p := range m.Processes {
+		nodes[p.Pid] = &processRow{ProcessStat: p, gpuMemory: m.GpuMemory[p.Pid]}
+	}
+
+	children := make(map[int][]*processRow)
+	var roots []*processRow
+	for _, p := range m.Processes {
+		node := nodes[p.Pid]
+		if _, ok := nodes[p.PPid]; ok && p.PPid != p.Pid {
+			children[p.PPid] = append(children[p.PPid], node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	filter := strings.ToLower(m.Filter)
+
+	// Total up each subtree and note whether anything in it matches
+	matches := make(map[int]bool)
+	var total func(n *processRow) bool
+	total = func(n *processRow) bool {
+		n.treeCpu = n.CpuPercent
+		n.treeRss = n.RSS
+		matched := filter == "" || processMatches(n.ProcessStat, filter)
+		for _, c := range children[n.Pid] {
+			if total(c) {
+				matched = true
+			}
+			n.treeCpu += c.treeCpu
+			n.treeRss += c.treeRss
+		}
+		matches[n.Pid] = matched
+		return matched
+	}
+	for _, r := range roots {
+		total(r)
+	}
+
+	sorted := func(nodes []*processRow) []*processRow {
+		var rows []processRow
+		for _, n := range nodes {
+			if matches[n.Pid] {
+				rows = append(rows, *n)
+			}
+		}
+		m.sortRows(rows)
+		out := make([]*processRow, len(rows))
+		for i := range rows {
+			out[i] = &rows[i]
+		}
+		return out
+	}
+
+	var rows []processRow
+	var walk func(n *processRow, indent string, last, root bool)
+	walk = func(n *processRow, indent string, last, root bool) {
+		kids := sorted(children[n.Pid])
+
+		row := *n
+		row.children = len(kids)
+		row.collapsed = m.collapsed[n.Pid]
+		childIndent := indent
+		if !root {
+			if last {
+				row.prefix = indent + "└─ "
+				childIndent = indent + "   "
+			} else {
+				row.prefix = indent + "├─ "
+				childIndent = indent + "│  "
+			}
+		}
+		rows = append(rows, row)
+
+		if row.collapsed {
+			return
+		}
+		for i, c := range kids {
+			walk(c, childIndent, i == len(kids)-1, false)
+		}
+	}
+	for _, r := range sorted(roots) {
+		walk(r, "", true, true)
+	}
+	return rows
+}
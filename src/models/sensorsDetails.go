@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+
+	"go-test/src/collector"
+	"go-test/src/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type SensorsModel struct {
+	Sensors collector.SensorsSample
+	Err     error
+	Loaded  bool
+}
+
+func NewSensorsModel() SensorsModel {
+	return SensorsModel{}
+}
+
+func (m SensorsModel) Update(msg tea.Msg) (SensorsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SampleMsg:
+		if msg.Name != collector.Sensors {
+			return m, nil
+		}
+		m.Loaded = true
+		m.Err = msg.Err
+		m.Sensors, _ = msg.Sample.(collector.SensorsSample)
+	}
+	return m, nil
+}
+
+func (m SensorsModel) View() string {
+	title := styles.TitleStyle.Render("SENSORS")
+
+	var content string
+	switch {
+	case !m.Loaded:
+		content = styles.StatKeyStyle.Render("Loading...")
+	case m.Err != nil:
+		content = styles.StatValueStyle.Foreground(styles.ColorError).Render(m.Err.Error())
+	case len(m.Sensors.Sensors) == 0:
+		content = styles.StatKeyStyle.Render("No sensors...")
+	default:
+		content = m.renderSensors()
+	}
+
+	box := styles.StatBoxStyle.Render(content)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, box)
+}
+
+// renderSensors lists the readings under a heading for each hwmon device.
+func (m SensorsModel) renderSensors() string {
+	var lines []string
+	device := ""
+	for _, r := range m.Sensors.Sensors {
+		if r.Device != device {
+			device = r.Device
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, styles.TableHeaderStyle.Padding(0).Render(fmt.Sprintf("%-28s %12s %12s", r.Chip+" ("+r.Device+")", "VALUE", "CRIT")))
+		}
+
+		label := r.Label
+		if r.ID() == m.Sensors.CpuPackage {
+			label += " (CPU)"
+		}
+		crit := "-"
+		if r.Crit > 0 {
+			crit = formatSensor(r.Kind, r.Crit)
+		}
+		row := styles.TableCellStyle.Padding(0).Render(fmt.Sprintf("%-28s ", truncate(label, 28)))
+		if r.Kind == collector.TempSensor {
+			row += styles.StatValueStyle.Foreground(styles.GetTempColor(r.Value)).Render(fmt.Sprintf("%12s", formatSensor(r.Kind, r.Value)))
+		} else {
+			row += styles.StatValueStyle.Render(fmt.Sprintf("%12s", formatSensor(r.Kind, r.Value)))
+		}
+		row += styles.TableCellStyle.Padding(0).Render(fmt.Sprintf(" %12s  ", crit))
+		if r.Kind == collector.TempSensor {
+			// Against the critical point when known, else against 100 °C
+			limit := r.Crit
+			if limit <= 0 {
+				limit = 100
+			}
+			row += styles.RenderProgressBar(20, min(100, r.Value/limit*100))
+		}
+		lines = append(lines, row)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func formatSensor(kind string, value float64) string {
+	switch kind {
+	case collector.TempSensor:
+		return fmt.Sprintf("%.1f °C", value)
+	case collector.FanSensor:
+		return fmt.Sprintf("%.0f RPM", value)
+	}
+	return fmt.Sprintf("%.3f V", value)
+}
@@ -0,0 +1,78 @@
+// Package procctl sends signals to and changes the priority of processes.
+package procctl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// Signals are the signals that may be sent to a process, by name.
+var Signals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"KILL": syscall.SIGKILL,
+	"STOP": syscall.SIGSTOP,
+	"CONT": syscall.SIGCONT,
+}
+
+// Nice values accepted by Renice.
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// ParseSignal looks up a signal by name, with or without the SIG prefix.
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.TrimPrefix(strings.ToUpper(name), "SIG")
+	sig, ok := Signals[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported signal %q, want one of %s", name, strings.Join(SignalNames(), ", "))
+	}
+	return sig, nil
+}
+
+// SignalNames returns the names of the supported signals, sorted.
+func SignalNames() []string {
+	names := make([]string, 0, len(Signals))
+	for name := range Signals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Signal sends sig to pid. The returned error wraps the errno, so callers can
+// check for syscall.EPERM or syscall.ESRCH with errors.Is.
+func Signal(pid int, sig syscall.Signal) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+	if err := syscall.Kill(pid, sig); err != nil {
+		return fmt.Errorf("send %s to %d: %w", sigName(sig), pid, err)
+	}
+	return nil
+}
+
+// Renice sets the nice value of pid.
+func Renice(pid, nice int) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+	if nice < MinNice || nice > MaxNice {
+		return fmt.Errorf("nice value %d out of range [%d, %d]", nice, MinNice, MaxNice)
+	}
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, pid, nice); err != nil {
+		return fmt.Errorf("renice %d to %d: %w", pid, nice, err)
+	}
+	return nil
+}
+
+func sigName(sig syscall.Signal) string {
+	for name, s := range Signals {
+		if s == sig {
+			return "SIG" + name
+		}
+	}
+	return sig.String()
+}
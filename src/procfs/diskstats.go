@@ -0,0 +1,66 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// SectorSize is the unit of the sector counters in /proc/diskstats, which
+// is always 512 bytes regardless of the device.
+const SectorSize = 512
+
+// DiskStat holds the I/O counters of one block device from /proc/diskstats.
+// Times are in milliseconds.
+type DiskStat struct {
+	Name string
+
+	ReadsCompleted  uint64
+	ReadsMerged     uint64
+	SectorsRead     uint64
+	ReadTime        uint64
+	WritesCompleted uint64
+	WritesMerged    uint64
+	SectorsWritten  uint64
+	WriteTime       uint64
+	InFlight        uint64
+	IOTime          uint64 // time the device was busy
+	WeightedIOTime  uint64
+}
+
+func (fs FS) DiskStats() ([]DiskStat, error) {
+	f, err := os.Open(fs.Path("diskstats"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var stats []DiskStat
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// major minor name followed by at least 11 counters
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+		v := make([]uint64, 11)
+		for i := range v {
+			v[i] = parseUint(fields[3+i])
+		}
+		stats = append(stats, DiskStat{
+			Name:            fields[2],
+			ReadsCompleted:  v[0],
+			ReadsMerged:     v[1],
+			SectorsRead:     v[2],
+			ReadTime:        v[3],
+			WritesCompleted: v[4],
+			WritesMerged:    v[5],
+			SectorsWritten:  v[6],
+			WriteTime:       v[7],
+			InFlight:        v[8],
+			IOTime:          v[9],
+			WeightedIOTime:  v[10],
+		})
+	}
+	return stats, scanner.Err()
+}
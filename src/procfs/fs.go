@@ -0,0 +1,66 @@
+// Package procfs reads process and system information from a mounted proc
+// filesystem.
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultMountPoint is where the proc filesystem is normally mounted.
+const DefaultMountPoint = "/proc"
+
+// FS is a proc filesystem mounted at root.
+type FS struct {
+	root string
+}
+
+func NewFS(root string) FS {
+	return FS{root: root}
+}
+
+func NewDefaultFS() FS {
+	return NewFS(DefaultMountPoint)
+}
+
+// Path joins elem onto the mount point.
+func (fs FS) Path(elem ...string) string {
+	return filepath.Join(append([]string{fs.root}, elem...)...)
+}
+
+// readKeyValues parses files made of "Key: value [kB]" lines, such as
+// /proc/[pid]/status and /proc/meminfo. Values with a kB suffix are
+// converted to bytes.
+func readKeyValues(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if v, found := strings.CutSuffix(value, " kB"); found {
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+				value = strconv.FormatUint(n*1024, 10)
+			}
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// parseUint returns 0 for missing or malformed values.
+func parseUint(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
@@ -0,0 +1,43 @@
+package procfs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadAvg holds the load averages from /proc/loadavg.
+type LoadAvg struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+
+	// Runnable and total scheduling entities (tasks).
+	Runnable uint64
+	Tasks    uint64
+}
+
+func (fs FS) LoadAvg() (LoadAvg, error) {
+	data, err := os.ReadFile(fs.Path("loadavg"))
+	if err != nil {
+		return LoadAvg{}, err
+	}
+
+	// 0.52 0.58 0.59 2/1234 56789
+	fields := strings.Fields(string(data))
+	if len(fields) < 4 {
+		return LoadAvg{}, fmt.Errorf("malformed loadavg: %q", data)
+	}
+
+	var l LoadAvg
+	for i, dst := range []*float64{&l.Load1, &l.Load5, &l.Load15} {
+		if *dst, err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return LoadAvg{}, fmt.Errorf("malformed loadavg: %w", err)
+		}
+	}
+	runnable, tasks, _ := strings.Cut(fields[3], "/")
+	l.Runnable = parseUint(runnable)
+	l.Tasks = parseUint(tasks)
+	return l, nil
+}
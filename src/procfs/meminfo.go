@@ -0,0 +1,67 @@
+package procfs
+
+// Meminfo holds the fields of /proc/meminfo that we use, in bytes. HugePages
+// counts are in pages of HugePageSize.
+type Meminfo struct {
+	MemTotal     uint64
+	MemFree      uint64
+	MemAvailable uint64
+	Buffers      uint64
+	Cached       uint64
+	Shmem        uint64
+	SReclaimable uint64
+	SUnreclaim   uint64
+	Active       uint64
+	Inactive     uint64
+	Dirty        uint64
+	Writeback    uint64
+
+	SwapTotal  uint64
+	SwapFree   uint64
+	SwapCached uint64
+
+	// Zswap is the size of the compressed pool, Zswapped the size of the
+	// pages stored in it. Both are 0 before Linux 5.19.
+	Zswap    uint64
+	Zswapped uint64
+
+	HugePagesTotal uint64
+	HugePagesFree  uint64
+	HugePagesRsvd  uint64
+	HugePagesSurp  uint64
+	HugePageSize   uint64
+}
+
+func (fs FS) Meminfo() (Meminfo, error) {
+	values, err := readKeyValues(fs.Path("meminfo"))
+	if err != nil {
+		return Meminfo{}, err
+	}
+	return Meminfo{
+		MemTotal:     parseUint(values["MemTotal"]),
+		MemFree:      parseUint(values["MemFree"]),
+		MemAvailable: parseUint(values["MemAvailable"]),
+		Buffers:      parseUint(values["Buffers"]),
+		Cached:       parseUint(values["Cached"]),
+		Shmem:        parseUint(values["Shmem"]),
+		SReclaimable: parseUint(values["SReclaimable"]),
+		SUnreclaim:   parseUint(values["SUnreclaim"]),
+		Active:       parseUint(values["Active"]),
+		Inactive:     parseUint(values["Inactive"]),
+		Dirty:        parseUint(values["Dirty"]),
+		Writeback:    parseUint(values["Writeback"]),
+
+		SwapTotal:  parseUint(values["SwapTotal"]),
+		SwapFree:   parseUint(values["SwapFree"]),
+		SwapCached: parseUint(values["SwapCached"]),
+
+		Zswap:    parseUint(values["Zswap"]),
+		Zswapped: parseUint(values["Zswapped"]),
+
+		HugePagesTotal: parseUint(values["HugePages_Total"]),
+		HugePagesFree:  parseUint(values["HugePages_Free"]),
+		HugePagesRsvd:  parseUint(values["HugePages_Rsvd"]),
+		HugePagesSurp:  parseUint(values["HugePages_Surp"]),
+		HugePageSize:   parseUint(values["Hugepagesize"]),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Mount is one entry of /proc/mounts.
+type Mount struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    []string
+}
+
+func (fs FS) Mounts() ([]Mount, error) {
+	f, err := os.Open(fs.Path("mounts"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []Mount
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// device mountpoint fstype options dump pass
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, Mount{
+			Device:     unescapeMount(fields[0]),
+			MountPoint: unescapeMount(fields[1]),
+			FSType:     fields[2],
+			Options:    strings.Split(fields[3], ","),
+		})
+	}
+	return mounts, scanner.Err()
+}
+
+// unescapeMount decodes the octal escapes the kernel uses for whitespace
+// and backslashes in mount paths, e.g. "\040" for a space.
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// NetDevLine holds the counters of one interface in /proc/net/dev.
+type NetDevLine struct {
+	Name      string
+	RxBytes   uint64
+	RxPackets uint64
+	TxBytes   uint64
+	TxPackets uint64
+}
+
+// NetDev reads the interfaces of the network namespace of the process,
+// which may differ from ours for containers.
+func (p Proc) NetDev() ([]NetDevLine, error) {
+	return readNetDev(p.path("net", "dev"))
+}
+
+// NetNamespace returns the network namespace of the process, e.g.
+// "net:[4026531840]". Processes in the same namespace share interfaces.
+func (p Proc) NetNamespace() (string, error) {
+	return os.Readlink(p.path("ns", "net"))
+}
+
+// SelfNetNamespace returns the network namespace of the running process.
+func (fs FS) SelfNetNamespace() (string, error) {
+	return os.Readlink(fs.Path("self", "ns", "net"))
+}
+
+func readNetDev(path string) ([]NetDevLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []NetDevLine
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// name: rx bytes packets errs drop fifo frame compressed multicast tx bytes packets ...
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(rest)
+		if !ok || len(fields) < 16 {
+			continue
+		}
+		lines = append(lines, NetDevLine{
+			Name:      strings.TrimSpace(name),
+			RxBytes:   parseUint(fields[0]),
+			RxPackets: parseUint(fields[1]),
+			TxBytes:   parseUint(fields[8]),
+			TxPackets: parseUint(fields[9]),
+		})
+	}
+	return lines, scanner.Err()
+}
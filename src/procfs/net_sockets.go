@@ -0,0 +1,87 @@
+package procfs
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NetSocket is one socket of /proc/net/tcp, tcp6, udp or udp6.
+type NetSocket struct {
+	LocalAddr  net.IP
+	LocalPort  int
+	RemoteAddr net.IP
+	RemotePort int
+	State      int // TCP_* of include/net/tcp_states.h, also used for UDP
+	TxQueue    uint64
+	RxQueue    uint64
+	UID        int
+	Inode      uint64 // 0 for sockets no longer owned, e.g. in TIME_WAIT
+}
+
+// NetSockets reads the socket table of protocol, one of "tcp", "tcp6",
+// "udp" and "udp6". The IPv6 tables are missing when IPv6 is disabled.
+func (fs FS) NetSockets(protocol string) ([]NetSocket, error) {
+	path := fs.Path("net", protocol)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sockets []NetSocket
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		s := NetSocket{}
+		var ok1, ok2 bool
+		s.LocalAddr, s.LocalPort, ok1 = parseSocketAddr(fields[1])
+		s.RemoteAddr, s.RemotePort, ok2 = parseSocketAddr(fields[2])
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if !ok1 || !ok2 || err != nil {
+			return nil, fmt.Errorf("malformed %s: %q", path, scanner.Text())
+		}
+		s.State = int(state)
+		if tx, rx, ok := strings.Cut(fields[4], ":"); ok {
+			s.TxQueue, _ = strconv.ParseUint(tx, 16, 64)
+			s.RxQueue, _ = strconv.ParseUint(rx, 16, 64)
+		}
+		s.UID, _ = strconv.Atoi(fields[7])
+		s.Inode = parseUint(fields[9])
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// parseSocketAddr parses an "address:port" pair of the socket tables. The
+// address is hexadecimal, made of 32-bit words in host byte order, and the
+// port is hexadecimal.
+func parseSocketAddr(s string) (net.IP, int, bool) {
+	addr, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, 0, false
+	}
+	raw, err := hex.DecodeString(addr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, false
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.NativeEndian.PutUint32(ip[i:], binary.BigEndian.Uint32(raw[i:]))
+	}
+	p, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return nil, 0, false
+	}
+	return ip, int(p), true
+}
@@ -0,0 +1,99 @@
+package procfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PSILine is one line of a pressure file: the share of wall time in which
+// tasks were stalled, averaged over 10, 60 and 300 seconds, in percent, and
+// the total stall time in microseconds.
+type PSILine struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  uint64  `json:"total"`
+}
+
+// Window returns the average over the given number of seconds, which must
+// be 10, 60 or 300.
+func (l PSILine) Window(seconds int) (float64, bool) {
+	switch seconds {
+	case 10:
+		return l.Avg10, true
+	case 60:
+		return l.Avg60, true
+	case 300:
+		return l.Avg300, true
+	}
+	return 0, false
+}
+
+// PSIStats is the pressure of one resource. "some" counts time in which at
+// least one task was stalled, "full" time in which all non-idle tasks were.
+// Full is nil for the system-wide CPU on kernels before 5.13.
+type PSIStats struct {
+	Some PSILine  `json:"some"`
+	Full *PSILine `json:"full,omitempty"`
+}
+
+// Pressure reads /proc/pressure/<resource>, where resource is "cpu",
+// "memory" or "io".
+func (fs FS) Pressure(resource string) (PSIStats, error) {
+	return ReadPSI(fs.Path("pressure", resource))
+}
+
+// ReadPSI parses a pressure file, either from /proc/pressure or a cgroup's
+// <resource>.pressure file.
+func ReadPSI(path string) (PSIStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return PSIStats{}, err
+	}
+	defer f.Close()
+
+	var s PSIStats
+	var found bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 5 {
+			continue
+		}
+		var l PSILine
+		for _, kv := range fields[1:] {
+			key, value, _ := strings.Cut(kv, "=")
+			switch key {
+			case "avg10":
+				l.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				l.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				l.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				l.Total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return PSIStats{}, fmt.Errorf("malformed pressure line %q: %w", scanner.Text(), err)
+			}
+		}
+		switch fields[0] {
+		case "some":
+			s.Some = l
+			found = true
+		case "full":
+			s.Full = &l
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return PSIStats{}, err
+	}
+	if !found {
+		return PSIStats{}, fmt.Errorf("no pressure data in %s", path)
+	}
+	return s, nil
+}
@@ -0,0 +1,222 @@
+package procfs
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Proc is a single process.
+type Proc struct {
+	PID int
+
+	fs FS
+}
+
+func (fs FS) Proc(pid int) Proc {
+	return Proc{PID: pid, fs: fs}
+}
+
+// AllProcs returns every process currently visible, sorted by PID.
+func (fs FS) AllProcs() ([]Proc, error) {
+	entries, err := os.ReadDir(fs.root)
+	if err != nil {
+		return nil, err
+	}
+
+	procs := make([]Proc, 0, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		procs = append(procs, fs.Proc(pid))
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+	return procs, nil
+}
+
+func (p Proc) path(elem ...string) string {
+	return p.fs.Path(append([]string{strconv.Itoa(p.PID)}, elem...)...)
+}
+
+// ProcStat holds the fields of /proc/[pid]/stat that we use. Times are in
+// clock ticks.
+type ProcStat struct {
+	PID        int
+	Comm       string
+	State      string
+	PPID       int
+	UTime      uint64
+	STime      uint64
+	Priority   int64
+	Nice       int64
+	NumThreads int64
+	StartTime  uint64
+	VSize      uint64
+	RSS        int64 // in pages
+}
+
+// CPUTime returns the ticks spent in user and kernel mode.
+func (s ProcStat) CPUTime() uint64 {
+	return s.UTime + s.STime
+}
+
+func (p Proc) Stat() (ProcStat, error) {
+	data, err := os.ReadFile(p.path("stat"))
+	if err != nil {
+		return ProcStat{}, err
+	}
+
+	// comm is wrapped in parentheses and may itself contain spaces or
+	// parentheses, so split around the last closing one.
+	lparen := bytes.IndexByte(data, '(')
+	rparen := bytes.LastIndexByte(data, ')')
+	if lparen < 0 || rparen < lparen {
+		return ProcStat{}, fmt.Errorf("malformed %s", p.path("stat"))
+	}
+
+	s := ProcStat{
+		PID:  p.PID,
+		Comm: string(data[lparen+1 : rparen]),
+	}
+
+	// Fields after comm, starting at field 3 (state).
+	fields := strings.Fields(string(data[rparen+1:]))
+	if len(fields) < 22 {
+		return ProcStat{}, fmt.Errorf("malformed %s", p.path("stat"))
+	}
+	s.State = fields[0]
+	s.PPID, _ = strconv.Atoi(fields[1])
+	s.UTime = parseUint(fields[11])
+	s.STime = parseUint(fields[12])
+	s.Priority, _ = strconv.ParseInt(fields[15], 10, 64)
+	s.Nice, _ = strconv.ParseInt(fields[16], 10, 64)
+	s.NumThreads, _ = strconv.ParseInt(fields[17], 10, 64)
+	s.StartTime = parseUint(fields[19])
+	s.VSize = parseUint(fields[20])
+	s.RSS, _ = strconv.ParseInt(fields[21], 10, 64)
+	return s, nil
+}
+
+// ProcStatus holds the fields of /proc/[pid]/status that we use. Memory is
+// in bytes.
+type ProcStatus struct {
+	Name    string
+	State   string
+	UID     int
+	Threads int
+	VmRSS   uint64
+}
+
+func (p Proc) Status() (ProcStatus, error) {
+	values, err := readKeyValues(p.path("status"))
+	if err != nil {
+		return ProcStatus{}, err
+	}
+
+	s := ProcStatus{
+		Name:  values["Name"],
+		State: values["State"],
+		VmRSS: parseUint(values["VmRSS"]),
+	}
+	// Uid: real, effective, saved set, filesystem
+	if uids := strings.Fields(values["Uid"]); len(uids) > 0 {
+		s.UID, _ = strconv.Atoi(uids[0])
+	}
+	s.Threads, _ = strconv.Atoi(values["Threads"])
+	return s, nil
+}
+
+// Cmdline returns the command line arguments. It is empty for kernel
+// threads and zombies.
+func (p Proc) Cmdline() ([]string, error) {
+	data, err := os.ReadFile(p.path("cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(data), "\x00"), nil
+}
+
+// SmapsRollup holds the summed memory usage of all mappings, in bytes.
+type SmapsRollup struct {
+	Rss uint64
+	Pss uint64
+}
+
+// SmapsRollup reads /proc/[pid]/smaps_rollup. It is usually only readable
+// for our own processes unless running as root.
+func (p Proc) SmapsRollup() (SmapsRollup, error) {
+	values, err := readKeyValues(p.path("smaps_rollup"))
+	if err != nil {
+		return SmapsRollup{}, err
+	}
+	return SmapsRollup{
+		Rss: parseUint(values["Rss"]),
+		Pss: parseUint(values["Pss"]),
+	}, nil
+}
+
+// Cwd returns the current working directory.
+func (p Proc) Cwd() (string, error) {
+	return os.Readlink(p.path("cwd"))
+}
+
+// Environ returns the initial environment as KEY=value strings.
+func (p Proc) Environ() ([]string, error) {
+	data, err := os.ReadFile(p.path("environ"))
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return strings.Split(string(data), "\x00"), nil
+}
+
+// FDCount returns the number of open file descriptors.
+func (p Proc) FDCount() (int, error) {
+	entries, err := os.ReadDir(p.path("fd"))
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// Cgroups returns the cgroup paths of the process, one per hierarchy, e.g.
+// "0::/user.slice/user-1000.slice".
+func (p Proc) Cgroups() ([]string, error) {
+	data, err := os.ReadFile(p.path("cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// SocketInodes returns the inodes of the sockets the process has open. It
+// needs the same permissions as reading /proc/[pid]/fd.
+func (p Proc) SocketInodes() ([]uint64, error) {
+	entries, err := os.ReadDir(p.path("fd"))
+	if err != nil {
+		return nil, err
+	}
+	var inodes []uint64
+	for _, e := range entries {
+		target, err := os.Readlink(p.path("fd", e.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			inodes = append(inodes, parseUint(strings.TrimSuffix(inode, "]")))
+		}
+	}
+	return inodes, nil
+}
@@ -0,0 +1,30 @@
+package procfs
+
+// ProcIO holds the I/O counters of /proc/[pid]/io, in bytes. RChar and
+// WChar count everything passed through read and write calls, ReadBytes and
+// WriteBytes only what actually hit the storage layer.
+type ProcIO struct {
+	RChar               uint64
+	WChar               uint64
+	SyscR               uint64
+	SyscW               uint64
+	ReadBytes           uint64
+	WriteBytes          uint64
+	CancelledWriteBytes uint64
+}
+
+func (p Proc) IO() (ProcIO, error) {
+	values, err := readKeyValues(p.path("io"))
+	if err != nil {
+		return ProcIO{}, err
+	}
+	return ProcIO{
+		RChar:               parseUint(values["rchar"]),
+		WChar:               parseUint(values["wchar"]),
+		SyscR:               parseUint(values["syscr"]),
+		SyscW:               parseUint(values["syscw"]),
+		ReadBytes:           parseUint(values["read_bytes"]),
+		WriteBytes:          parseUint(values["write_bytes"]),
+		CancelledWriteBytes: parseUint(values["cancelled_write_bytes"]),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MapsSummary totals the virtual memory mappings of a process by kind, in
+// bytes.
+type MapsSummary struct {
+	Count int
+	Total uint64
+	File  uint64 // backed by a file, including shared libraries
+	Anon  uint64 // anonymous memory other than heap and stack
+	Heap  uint64
+	Stack uint64
+	Other uint64 // special mappings such as [vdso] and [vvar]
+}
+
+func (p Proc) MapsSummary() (MapsSummary, error) {
+	f, err := os.Open(p.path("maps"))
+	if err != nil {
+		return MapsSummary{}, err
+	}
+	defer f.Close()
+
+	var s MapsSummary
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// address perms offset dev inode [pathname]
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		start, end, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			continue
+		}
+		lo, err1 := strconv.ParseUint(start, 16, 64)
+		hi, err2 := strconv.ParseUint(end, 16, 64)
+		if err1 != nil || err2 != nil || hi < lo {
+			continue
+		}
+		size := hi - lo
+
+		path := ""
+		if len(fields) > 5 {
+			path = fields[5]
+		}
+
+		s.Count++
+		s.Total += size
+		switch {
+		case path == "":
+			s.Anon += size
+		case path == "[heap]":
+			s.Heap += size
+		case strings.HasPrefix(path, "[stack"):
+			s.Stack += size
+		case strings.HasPrefix(path, "["):
+			s.Other += size
+		default:
+			s.File += size
+		}
+	}
+	return s, scanner.Err()
+}
@@ -0,0 +1,30 @@
+package procfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProcStatCommWithSpaces(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "42")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stat := "42 (Web (Content) x) S 1 42 42 0 -1 4194304 80 0 0 0 150 50 0 0 20 5 7 0 112958 2703360 313 18446744073709551615\n"
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewFS(root).Proc(42).Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Comm != "Web (Content) x" {
+		t.Errorf("Comm = %q, want %q", s.Comm, "Web (Content) x")
+	}
+	if s.State != "S" || s.PPID != 1 || s.CPUTime() != 200 || s.Nice != 5 || s.NumThreads != 7 || s.RSS != 313 {
+		t.Errorf("unexpected stat: %+v", s)
+	}
+}
@@ -0,0 +1,119 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// UserHZ is the unit of the clock tick counters in procfs. It is fixed at
+// 100 on every architecture Linux supports.
+const UserHZ = 100
+
+// CpuStat holds the time spent in each mode, in clock ticks.
+type CpuStat struct {
+	// ID is the logical CPU number, or -1 for the system-wide total.
+	ID int
+
+	User      uint64
+	Nice      uint64
+	System    uint64
+	Idle      uint64
+	Iowait    uint64
+	IRQ       uint64
+	SoftIRQ   uint64
+	Steal     uint64
+	Guest     uint64
+	GuestNice uint64
+}
+
+// Total returns the sum of all modes. Guest time is already accounted for
+// in User and Nice.
+func (c CpuStat) Total() uint64 {
+	return c.User + c.Nice + c.System + c.Idle + c.Iowait + c.IRQ + c.SoftIRQ + c.Steal
+}
+
+// Stat holds the system-wide statistics from /proc/stat.
+type Stat struct {
+	CpuTotal CpuStat
+	Cpu      []CpuStat
+
+	// BootTime is the boot time in seconds since the Unix epoch.
+	BootTime uint64
+
+	// Cumulative counts since boot.
+	ContextSwitches uint64
+	Interrupts      uint64
+
+	ProcsRunning uint64
+	ProcsBlocked uint64
+}
+
+func (fs FS) Stat() (Stat, error) {
+	f, err := os.Open(fs.Path("stat"))
+	if err != nil {
+		return Stat{}, err
+	}
+	defer f.Close()
+
+	var s Stat
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "btime":
+			s.BootTime = parseUint(fields[1])
+			continue
+		case "ctxt":
+			s.ContextSwitches = parseUint(fields[1])
+			continue
+		case "intr":
+			// The total comes first, followed by one count per IRQ
+			s.Interrupts = parseUint(fields[1])
+			continue
+		case "procs_running":
+			s.ProcsRunning = parseUint(fields[1])
+			continue
+		case "procs_blocked":
+			s.ProcsBlocked = parseUint(fields[1])
+			continue
+		}
+		if !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		c := parseCpuStat(fields[1:])
+		if fields[0] == "cpu" {
+			c.ID = -1
+			s.CpuTotal = c
+		} else if id, err := strconv.Atoi(fields[0][len("cpu"):]); err == nil {
+			// Offline CPUs have no line, so IDs may have gaps
+			c.ID = id
+			s.Cpu = append(s.Cpu, c)
+		}
+	}
+	return s, scanner.Err()
+}
+
+func parseCpuStat(fields []string) CpuStat {
+	v := make([]uint64, 10)
+	for i := 0; i < len(fields) && i < len(v); i++ {
+		v[i] = parseUint(fields[i])
+	}
+	return CpuStat{
+		User:      v[0],
+		Nice:      v[1],
+		System:    v[2],
+		Idle:      v[3],
+		Iowait:    v[4],
+		IRQ:       v[5],
+		SoftIRQ:   v[6],
+		Steal:     v[7],
+		Guest:     v[8],
+		GuestNice: v[9],
+	}
+}
@@ -0,0 +1,41 @@
+package procfs
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// VMStat holds the counters of /proc/vmstat that we use. They count pages
+// since boot.
+type VMStat struct {
+	PswpIn     uint64 // pages swapped in
+	PswpOut    uint64 // pages swapped out
+	PgMajFault uint64
+}
+
+func (fs FS) VMStat() (VMStat, error) {
+	f, err := os.Open(fs.Path("vmstat"))
+	if err != nil {
+		return VMStat{}, err
+	}
+	defer f.Close()
+
+	var s VMStat
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "pswpin":
+			s.PswpIn = parseUint(value)
+		case "pswpout":
+			s.PswpOut = parseUint(value)
+		case "pgmajfault":
+			s.PgMajFault = parseUint(value)
+		}
+	}
+	return s, scanner.Err()
+}
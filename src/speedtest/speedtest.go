@@ -0,0 +1,276 @@
+// Package speedtest measures the latency and throughput between this machine
+// and an HTTP server, such as a mirror serving a large file.
+package speedtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoURL is returned when no server was configured.
+var ErrNoURL = errors.New("no speedtest URL configured, set SPEEDTEST_URL")
+
+// Config describes the server and the length of a test.
+type Config struct {
+	// URL is fetched with HEAD to measure latency and with GET to measure
+	// the download. It should serve a large file.
+	URL string
+
+	// UploadURL accepts the POST requests of the upload, which is skipped
+	// when it is empty. Mirrors serving static files refuse POST, so it is
+	// not URL by default.
+	UploadURL string
+
+	Streams  int           // parallel connections per direction
+	Duration time.Duration // of the download and of the upload
+	Pings    int           // requests timed for the latency
+
+	// Client defaults to one with a connection per stream and without
+	// transparent decompression, so that wire bytes are counted.
+	Client *http.Client
+}
+
+// DefaultConfig reads the server from the SPEEDTEST_URL environment
+// variable and the number of streams from SPEEDTEST_STREAMS. The upload
+// only runs when SPEEDTEST_UPLOAD_URL is set.
+func DefaultConfig() Config {
+	cfg := Config{
+		URL:       os.Getenv("SPEEDTEST_URL"),
+		UploadURL: os.Getenv("SPEEDTEST_UPLOAD_URL"),
+		Streams:   4,
+		Duration:  10 * time.Second,
+		Pings:     10,
+	}
+	if n, err := strconv.Atoi(os.Getenv("SPEEDTEST_STREAMS")); err == nil && n > 0 {
+		cfg.Streams = n
+	}
+	return cfg
+}
+
+// Result is the outcome of a test. Speeds are in Mbit/s.
+type Result struct {
+	Latency  time.Duration // median round trip of a HEAD request
+	Jitter   time.Duration // mean difference between consecutive round trips
+	Download float64
+	Upload   float64 // 0 when skipped or failed
+
+	// UploadErr explains a failed upload, in which case the latency and
+	// download still stand.
+	UploadErr error
+
+	DownloadBytes int64
+	UploadBytes   int64
+}
+
+// uploadChunk is the size of the body of each upload request.
+const uploadChunk = 8 << 20
+
+// Run measures latency, then download, then upload. Any failure, including
+// a transfer that moved no data, is returned as an error instead of a zero
+// speed. A failed upload is reported in Result.UploadErr instead.
+func Run(ctx context.Context, cfg Config) (Result, error) {
+	if cfg.URL == "" {
+		return Result{}, ErrNoURL
+	}
+	cfg.Streams = max(1, cfg.Streams)
+	if cfg.Client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = cfg.Streams
+		transport.DisableCompression = true
+		cfg.Client = &http.Client{Transport: transport}
+		defer transport.CloseIdleConnections()
+	}
+
+	var r Result
+	var err error
+	if r.Latency, r.Jitter, err = ping(ctx, cfg); err != nil {
+		return Result{}, fmt.Errorf("latency: %w", err)
+	}
+	if r.Download, r.DownloadBytes, err = transfer(ctx, cfg, download); err != nil {
+		return Result{}, fmt.Errorf("download: %w", err)
+	}
+	if cfg.UploadURL != "" {
+		if r.Upload, r.UploadBytes, err = transfer(ctx, cfg, upload); err != nil {
+			r.Upload, r.UploadBytes = 0, 0
+			r.UploadErr = fmt.Errorf("upload: %w", err)
+		}
+	}
+	// Cancelled during the upload, the whole test was
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
+
+// ping times HEAD requests after a first one that opens the connection.
+func ping(ctx context.Context, cfg Config) (latency, jitter time.Duration, err error) {
+	pings := max(1, cfg.Pings)
+	rtts := make([]time.Duration, 0, pings)
+	for i := 0; i <= pings; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, cfg.URL, nil)
+		if err != nil {
+			return 0, 0, err
+		}
+		start := time.Now()
+		resp, err := cfg.Client.Do(req)
+		if err != nil {
+			return 0, 0, err
+		}
+		rtt := time.Since(start)
+		resp.Body.Close()
+		if err := checkStatus(resp); err != nil {
+			return 0, 0, err
+		}
+		if i > 0 {
+			rtts = append(rtts, rtt)
+		}
+	}
+
+	for i := 1; i < len(rtts); i++ {
+		jitter += (rtts[i] - rtts[i-1]).Abs()
+	}
+	if len(rtts) > 1 {
+		jitter /= time.Duration(len(rtts) - 1)
+	}
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	return rtts[len(rtts)/2], jitter, nil
+}
+
+// stream repeats requests until ctx is done, adding the bytes moved to n.
+type stream func(ctx context.Context, cfg Config, n *atomic.Int64) error
+
+// transfer runs cfg.Streams streams for cfg.Duration and returns the
+// throughput in Mbit/s.
+func transfer(ctx context.Context, cfg Config, run stream) (float64, int64, error) {
+	timed, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var n atomic.Int64
+	errs := make([]error, cfg.Streams)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range cfg.Streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = run(timed, cfg, &n)
+			if errs[i] != nil {
+				// One failed stream fails the test, so stop the others
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	bytes := n.Load()
+	if bytes == 0 {
+		return 0, 0, errors.New("no data transferred")
+	}
+	return float64(bytes) * 8 / elapsed.Seconds() / 1e6, bytes, nil
+}
+
+// download fetches cfg.URL again and again.
+func download(ctx context.Context, cfg Config, n *atomic.Int64) error {
+	buf := make([]byte, 64<<10)
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := cfg.Client.Do(req)
+		if err != nil {
+			return timedOut(ctx, err)
+		}
+		if err := checkStatus(resp); err != nil {
+			resp.Body.Close()
+			return err
+		}
+		for {
+			read, err := resp.Body.Read(buf)
+			n.Add(int64(read))
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				resp.Body.Close()
+				return timedOut(ctx, err)
+			}
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// upload posts chunks of zeros to cfg.UploadURL again and again, counting
+// the bytes as the client sends them.
+func upload(ctx context.Context, cfg Config, n *atomic.Int64) error {
+	for ctx.Err() == nil {
+		body := &countingReader{remaining: uploadChunk, n: n}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.UploadURL, body)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = uploadChunk
+		req.Header.Set("Content-Type", "application/octet-stream")
+		resp, err := cfg.Client.Do(req)
+		if err != nil {
+			return timedOut(ctx, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := checkStatus(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timedOut hides the errors caused by the end of the test.
+func timedOut(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status)
+	}
+	return nil
+}
+
+// countingReader yields remaining zero bytes, adding each read to n.
+type countingReader struct {
+	remaining int64
+	n         *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	size := int(min(int64(len(p)), r.remaining))
+	clear(p[:size])
+	r.remaining -= int64(size)
+	r.n.Add(int64(size))
+	return size, nil
+}
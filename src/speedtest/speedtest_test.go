@@ -0,0 +1,173 @@
+package speedtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer serves 1MB on GET and drains POST bodies, recording the most
+// requests in flight at once.
+type testServer struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	uploaded int64
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	switch r.Method {
+	case http.MethodHead:
+	case http.MethodGet:
+		w.Write(make([]byte, 1<<20))
+	case http.MethodPost:
+		n, _ := io.Copy(io.Discard, r.Body)
+		s.mu.Lock()
+		s.uploaded += n
+		s.mu.Unlock()
+	}
+}
+
+func testConfig(url string) Config {
+	return Config{URL: url, UploadURL: url, Streams: 3, Duration: 200 * time.Millisecond, Pings: 3}
+}
+
+func TestRun(t *testing.T) {
+	s := &testServer{}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	r, err := Run(context.Background(), testConfig(srv.URL))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	srv.Close() // waits for the handlers to return
+	if r.Latency <= 0 || r.Jitter < 0 {
+		t.Errorf("Latency = %v, Jitter = %v", r.Latency, r.Jitter)
+	}
+	if r.Download <= 0 || r.DownloadBytes < 1<<20 {
+		t.Errorf("Download = %v Mbit/s over %d bytes", r.Download, r.DownloadBytes)
+	}
+	if r.Upload <= 0 || r.UploadBytes <= 0 || r.UploadErr != nil {
+		t.Errorf("UploadErr = %v, Upload = %v Mbit/s over %d bytes", r.UploadErr, r.Upload, r.UploadBytes)
+	}
+	// Bytes are counted as they leave the client, the server may be behind
+	if s.uploaded == 0 || s.uploaded > r.UploadBytes {
+		t.Errorf("server received %d bytes, client sent %d", s.uploaded, r.UploadBytes)
+	}
+	if s.peak < 3 {
+		t.Errorf("peak requests in flight = %d, want 3 streams", s.peak)
+	}
+}
+
+func TestRunNoUpload(t *testing.T) {
+	s := &testServer{}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	cfg := testConfig(srv.URL)
+	cfg.UploadURL = ""
+	r, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	srv.Close()
+	if r.Download <= 0 {
+		t.Errorf("Download = %v, want a download", r.Download)
+	}
+	if r.Upload != 0 || r.UploadErr != nil || s.uploaded != 0 {
+		t.Errorf("Upload = %v (%v), server received %d bytes", r.Upload, r.UploadErr, s.uploaded)
+	}
+}
+
+func TestRunUploadRefused(t *testing.T) {
+	// A static mirror serves downloads but refuses uploads
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			http.Error(w, "read only", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write(make([]byte, 1024))
+	}))
+	defer srv.Close()
+
+	r, err := Run(context.Background(), testConfig(srv.URL))
+	if err != nil {
+		t.Fatalf("Run() error = %v, want the upload error in the result", err)
+	}
+	if r.Latency <= 0 || r.Download <= 0 {
+		t.Errorf("Latency = %v, Download = %v, want both kept", r.Latency, r.Download)
+	}
+	want := "upload: POST " + srv.URL + ": 405 Method Not Allowed"
+	if r.UploadErr == nil || r.UploadErr.Error() != want || r.Upload != 0 || r.UploadBytes != 0 {
+		t.Errorf("UploadErr = %v, Upload = %v over %d bytes, want %q", r.UploadErr, r.Upload, r.UploadBytes, want)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer empty.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"no url", "", ErrNoURL.Error()},
+		{"not found", notFound.URL, "latency: HEAD " + notFound.URL + ": 404 Not Found"},
+		{"empty body", empty.URL, "download: no data transferred"},
+		{"unreachable", closed.URL, "latency: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Run(context.Background(), testConfig(tt.url))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Fatalf("Run() error = %v, want %q", err, tt.want)
+			}
+			if r != (Result{}) {
+				t.Errorf("Run() = %+v on error, want zero", r)
+			}
+		})
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	srv := httptest.NewServer(&testServer{})
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := testConfig(srv.URL)
+	cfg.Duration = time.Minute
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := Run(ctx, cfg)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Errorf("Run() took %v after cancel", time.Since(start))
+	}
+}
@@ -81,6 +81,11 @@ var (
 	TableCellStyle = lipgloss.NewStyle().
 			Padding(0, 1).
 			Foreground(ColorText)
+
+	TableSelectedStyle = lipgloss.NewStyle().
+				Foreground(ColorBg).
+				Background(ColorPrimary).
+				Bold(true)
 )
 
 func RenderStat(key, value string) string {
@@ -142,6 +147,52 @@ func RenderProgressBar(width int, percentage float64) string {
 	return "[" + bar + "]"
 }
 
+// BarSegment is one part of a stacked bar.
+type BarSegment struct {
+	Value float64
+	Color lipgloss.Color
+}
+
+// RenderStackedBar draws segments side by side, each taking its share of
+// total. Rounding leftovers go to the last segment so the bar is always full.
+func RenderStackedBar(width int, total float64, segments ...BarSegment) string {
+	if total <= 0 || len(segments) == 0 {
+		return "[" + lipgloss.NewStyle().Foreground(ColorSubtext).Render(repeat("░", width)) + "]"
+	}
+
+	bar := ""
+	used := 0
+	for i, seg := range segments {
+		n := int(float64(width) * seg.Value / total)
+		if i == len(segments)-1 || used+n > width {
+			n = width - used
+		}
+		if n <= 0 {
+			continue
+		}
+		bar += lipgloss.NewStyle().Foreground(seg.Color).Render(repeat("█", n))
+		used += n
+	}
+	return "[" + bar + "]"
+}
+
+// sparkLevels are the block heights of a sparkline, lowest first.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// RenderSparkline draws values, oldest first, as block heights between lo
+// and hi. Values outside the range are clamped to it.
+func RenderSparkline(values []float64, lo, hi float64) string {
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkLevels)-1))
+		}
+		line[i] = sparkLevels[max(0, min(len(sparkLevels)-1, level))]
+	}
+	return lipgloss.NewStyle().Foreground(ColorCyan).Render(string(line))
+}
+
 func repeat(s string, count int) string {
 	res := ""
 	for i := 0; i < count; i++ {